@@ -106,6 +106,8 @@ func (h *Handler) execGamePost(c *gin.Context) {
 		}
 
 		go func() {
+			scheduler.BackupBeforeUpdate()
+
 			db.DstUpdating = true
 			updateCmd := fmt.Sprintf("cd ~/steamcmd && ./steamcmd.sh +login anonymous +force_install_dir ~/dst +app_update 343050 validate +quit")
 			_ = utils.BashCMD(updateCmd)
@@ -1,11 +1,20 @@
 package mod
 
 import (
+	"context"
+	"dst-management-platform-api/database/db"
+	"dst-management-platform-api/database/models"
 	"dst-management-platform-api/dst"
 	"dst-management-platform-api/logger"
+	"dst-management-platform-api/scheduler"
 	"dst-management-platform-api/utils"
+	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -63,13 +72,12 @@ func modSearchGet(c *gin.Context) {
 	c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 }
 
+// downloadPost 将模组加入下载队列，下载进度通过下载任务接口获取
 func (h *Handler) downloadPost(c *gin.Context) {
 	type ReqForm struct {
 		RoomID  int    `json:"roomID"`
 		ID      int    `json:"id"`
 		FileURL string `json:"file_url"`
-		Update  bool   `json:"update"`
-		Size    string `json:"size"`
 		Name    string `json:"name"`
 	}
 	var reqForm ReqForm
@@ -79,40 +87,20 @@ func (h *Handler) downloadPost(c *gin.Context) {
 		return
 	}
 
-	reqSize, err := strconv.Atoi(reqForm.Size)
-	if err != nil {
-		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
-		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
-		return
-	}
-
-	reqSize64 := int64(reqSize)
-
-	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
-	if err != nil {
+	if _, err := h.roomDao.GetRoomByID(reqForm.RoomID); err != nil {
 		logger.Logger.Error("获取基本信息失败", "err", err)
 		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
 		return
 	}
 
-	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
-	err, modSize := game.DownloadMod(reqForm.ID, reqForm.FileURL)
-	if err != nil || modSize != reqSize64 {
-		logger.Logger.DebugF("模组大小与预期不符, %d, %d", modSize, reqSize64)
-		if reqForm.Update {
-			c.JSON(http.StatusOK, gin.H{"code": 201, "message": reqForm.Name + " " + message.Get(c, "update fail"), "data": nil})
-			return
-		} else {
-			c.JSON(http.StatusOK, gin.H{"code": 201, "message": reqForm.Name + " " + message.Get(c, "download fail"), "data": nil})
-			return
-		}
+	job, err := scheduler.EnqueueModDownload(reqForm.RoomID, reqForm.ID, reqForm.Name, reqForm.FileURL)
+	if err != nil {
+		logger.Logger.Error("添加模组下载任务失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": reqForm.Name + " " + message.Get(c, "enqueue fail"), "data": nil})
+		return
 	}
 
-	if reqForm.Update {
-		c.JSON(http.StatusOK, gin.H{"code": 200, "message": reqForm.Name + " " + message.Get(c, "update success"), "data": nil})
-	} else {
-		c.JSON(http.StatusOK, gin.H{"code": 200, "message": reqForm.Name + " " + message.Get(c, "download success"), "data": nil})
-	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": reqForm.Name + " " + message.Get(c, "enqueue success"), "data": job})
 }
 
 func (h *Handler) downloadedModsGet(c *gin.Context) {
@@ -214,6 +202,7 @@ func (h *Handler) settingModConfigValuePut(c *gin.Context) {
 		WorldID     int             `json:"worldID"`
 		ID          int             `json:"id"`
 		ModORConfig dst.ModORConfig `json:"modORConfig"`
+		Force       bool            `json:"force"`
 	}
 	var reqForm ReqForm
 	if err := c.ShouldBindJSON(&reqForm); err != nil {
@@ -230,12 +219,16 @@ func (h *Handler) settingModConfigValuePut(c *gin.Context) {
 	}
 
 	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
-	err = game.ModConfigureOptionsValuesChange(reqForm.WorldID, reqForm.ID, &reqForm.ModORConfig)
+	errs, err := game.ModConfigureOptionsValuesChange(reqForm.WorldID, reqForm.ID, &reqForm.ModORConfig, reqForm.Force)
 	if err != nil {
 		logger.Logger.Error("修改模组设置失败")
 		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "modify mod configuration values error"), "data": nil})
 		return
 	}
+	if len(errs) != 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "mod configuration values invalid"), "data": errs})
+		return
+	}
 
 	err = h.roomDao.UpdateRoom(room)
 	if err != nil {
@@ -254,6 +247,84 @@ func (h *Handler) settingModConfigValuePut(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "modify mod configuration values success"), "data": nil})
 }
 
+// settingModConfigResetPost 将模组配置重置为默认值
+func (h *Handler) settingModConfigResetPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID  int `json:"roomID"`
+		WorldID int `json:"worldID"`
+		ID      int `json:"id"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	err = game.ModConfigReset(reqForm.WorldID, reqForm.ID)
+	if err != nil {
+		logger.Logger.Error("重置模组设置失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "reset mod configuration fail"), "data": nil})
+		return
+	}
+
+	err = h.roomDao.UpdateRoom(room)
+	if err != nil {
+		logger.Logger.Error("更新房间失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	err = h.worldDao.UpdateWorlds(worlds)
+	if err != nil {
+		logger.Logger.Error("更新房间失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "reset mod configuration success"), "data": nil})
+}
+
+// settingModConfigDiffGet 对比模组当前配置和默认配置
+func (h *Handler) settingModConfigDiffGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID  int `form:"roomID"`
+		WorldID int `form:"worldID"`
+		ID      int `form:"id"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	diffs, err := game.ModConfigDiff(reqForm.WorldID, reqForm.ID)
+	if err != nil {
+		logger.Logger.Error("对比模组设置失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "mod configuration values error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": diffs})
+}
+
 func (h *Handler) addEnablePost(c *gin.Context) {
 	type ReqForm struct {
 		RoomID  int    `json:"roomID"`
@@ -297,7 +368,13 @@ func (h *Handler) addEnablePost(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "mod enable success"), "data": nil})
+	// 启用成功后返回校验结果，提示依赖和兼容性问题
+	validation, err := game.ValidateMods(reqForm.WorldID)
+	if err != nil {
+		logger.Logger.Error("模组校验失败", "err", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "mod enable success"), "data": validation})
 }
 
 func (h *Handler) addDisablePost(c *gin.Context) {
@@ -410,3 +487,1141 @@ func (h *Handler) deletePost(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "delete success"), "data": nil})
 }
+
+func (h *Handler) outdatedGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID  int  `form:"roomID"`
+		Refresh bool `form:"refresh"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.Refresh {
+		room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+		if err != nil {
+			logger.Logger.Error("获取基本信息失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+
+		game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+		_, err = scheduler.CheckModUpdates(game, reqForm.RoomID)
+		if err != nil {
+			logger.Logger.Error("检查模组更新失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "check mod update fail"), "data": nil})
+			return
+		}
+	}
+
+	modVersions, err := h.modVersionDao.GetOutdatedModVersions(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": modVersions})
+}
+
+func (h *Handler) updatePost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID  int   `json:"roomID"`
+		IDs     []int `json:"ids"`
+		Restart bool  `json:"restart"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	modVersions, err := h.modVersionDao.GetOutdatedModVersions(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+
+	var jobs []*models.ModDownloadJob
+	for _, modVersion := range *modVersions {
+		// 未指定则更新全部过期模组
+		if len(reqForm.IDs) != 0 && !slices.Contains(reqForm.IDs, modVersion.ModID) {
+			continue
+		}
+		job, err := scheduler.EnqueueModDownload(reqForm.RoomID, modVersion.ModID, modVersion.Name, modVersion.FileURL)
+		if err != nil {
+			logger.Logger.Error("添加模组下载任务失败", "err", err, "mod", modVersion.ModID)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	if len(jobs) == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "no outdated mod"), "data": nil})
+		return
+	}
+
+	// 下载完成后重新记录模组版本，有模组更新成功时按需重启
+	go func() {
+		updated := 0
+		for _, job := range jobs {
+			job, err := scheduler.WaitModDownload(context.Background(), job.ID)
+			if err != nil {
+				logger.Logger.Error("等待模组下载任务失败", "err", err)
+				continue
+			}
+			if job.Status == models.ModDownloadSucceeded {
+				updated++
+			}
+		}
+
+		if _, err := scheduler.CheckModUpdates(game, reqForm.RoomID); err != nil {
+			logger.Logger.Error("检查模组更新失败", "err", err)
+		}
+		if reqForm.Restart && updated != 0 {
+			scheduler.Restart(game)
+		}
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "enqueue success"), "data": jobs})
+}
+
+func (h *Handler) validateGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID  int `form:"roomID"`
+		WorldID int `form:"worldID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	validation, err := game.ValidateMods(reqForm.WorldID)
+	if err != nil {
+		logger.Logger.Error("模组校验失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "mod validate fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": validation})
+}
+
+func (h *Handler) collectionImportPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID       int              `json:"roomID"`
+		CollectionID int              `json:"collectionID"`
+		Manifest     *dst.ModManifest `json:"manifest"`
+		WorldIDs     []int            `json:"worldIDs"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+	if reqForm.CollectionID == 0 && reqForm.Manifest == nil {
+		logger.Logger.Info("请求参数错误", "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	// 检查和占用在同一把锁内完成，避免同时发起两次导入，导入开始前失败时恢复原来的进度
+	db.ModImportProgressMutex.Lock()
+	lastStatus, hasLastStatus := db.ModImportProgress[reqForm.RoomID]
+	if hasLastStatus && lastStatus.StartedAt != 0 && !lastStatus.Done {
+		db.ModImportProgressMutex.Unlock()
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "mod importing"), "data": nil})
+		return
+	}
+	db.ModImportProgress[reqForm.RoomID] = db.ModImportStatus{
+		CollectionID: reqForm.CollectionID,
+		Succeeded:    []int{},
+		Failed:       []int{},
+		StartedAt:    utils.GetTimestamp(),
+	}
+	db.ModImportProgressMutex.Unlock()
+	releaseImport := func() {
+		db.ModImportProgressMutex.Lock()
+		if hasLastStatus {
+			db.ModImportProgress[reqForm.RoomID] = lastStatus
+		} else {
+			delete(db.ModImportProgress, reqForm.RoomID)
+		}
+		db.ModImportProgressMutex.Unlock()
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		releaseImport()
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	lang := c.Request.Header.Get("X-I18n-Lang")
+
+	// 解析合集或清单中的模组
+	var (
+		title     string
+		items     []dst.WorkshopItem
+		overrides = make(map[int]map[string]any)
+	)
+	if reqForm.Manifest != nil {
+		title = reqForm.Manifest.Name
+		var ids []int
+		for _, mod := range reqForm.Manifest.Mods {
+			ids = append(ids, mod.ID)
+			if len(mod.ConfigurationOptions) != 0 {
+				overrides[mod.ID] = mod.ConfigurationOptions
+			}
+		}
+		workshopItems, err := dst.Workshop.GetItems(ids, lang)
+		if err != nil {
+			releaseImport()
+			logger.Logger.Error("获取模组信息失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "get collection fail"), "data": nil})
+			return
+		}
+		for _, id := range ids {
+			item, ok := workshopItems[id]
+			if !ok {
+				item = dst.WorkshopItem{ID: id}
+			}
+			items = append(items, item)
+		}
+	} else {
+		collection, err := dst.Workshop.GetCollection(reqForm.CollectionID, lang)
+		if err != nil {
+			releaseImport()
+			logger.Logger.Error("获取创意工坊合集失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "get collection fail"), "data": nil})
+			return
+		}
+		title = collection.Title
+		items = collection.Children
+	}
+
+	db.ModImportProgressMutex.Lock()
+	status := db.ModImportProgress[reqForm.RoomID]
+	status.Title = title
+	status.Total = len(items)
+	db.ModImportProgress[reqForm.RoomID] = status
+	db.ModImportProgressMutex.Unlock()
+
+	game := dst.NewGameController(room, worlds, roomSetting, lang)
+	go func() {
+		err := game.ImportMods(items, reqForm.WorldIDs, overrides, func(item dst.WorkshopItem, err error) {
+			db.ModImportProgressMutex.Lock()
+			defer db.ModImportProgressMutex.Unlock()
+			status := db.ModImportProgress[reqForm.RoomID]
+			status.Finished++
+			status.Current = item.ID
+			if err != nil {
+				status.Failed = append(status.Failed, item.ID)
+			} else {
+				status.Succeeded = append(status.Succeeded, item.ID)
+			}
+			db.ModImportProgress[reqForm.RoomID] = status
+		})
+		if err != nil {
+			logger.Logger.Error("保存模组配置失败", "err", err)
+		}
+
+		if err = h.roomDao.UpdateRoom(room); err != nil {
+			logger.Logger.Error("更新房间失败", "err", err)
+		}
+		if err = h.worldDao.UpdateWorlds(worlds); err != nil {
+			logger.Logger.Error("更新世界失败", "err", err)
+		}
+
+		db.ModImportProgressMutex.Lock()
+		status := db.ModImportProgress[reqForm.RoomID]
+		status.Done = true
+		db.ModImportProgress[reqForm.RoomID] = status
+		db.ModImportProgressMutex.Unlock()
+		logger.Logger.Info("模组导入完成", "room", reqForm.RoomID, "succeeded", len(status.Succeeded), "failed", len(status.Failed))
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "mod import started"), "data": items})
+}
+
+func (h *Handler) collectionImportStatusGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int `form:"roomID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	db.ModImportProgressMutex.Lock()
+	status, ok := db.ModImportProgress[reqForm.RoomID]
+	db.ModImportProgressMutex.Unlock()
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": status})
+}
+
+func (h *Handler) collectionExportGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID     int  `form:"roomID"`
+		WorldID    int  `form:"worldID"`
+		WithConfig bool `form:"withConfig"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	manifest, err := game.ExportModManifest(reqForm.WorldID, reqForm.WithConfig)
+	if err != nil {
+		logger.Logger.Error("导出模组清单失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "export manifest fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": manifest})
+}
+
+// clientManifestGet 玩家需要订阅的客户端模组清单
+func (h *Handler) clientManifestGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int  `form:"roomID"`
+		All    bool `form:"all"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	lang := c.Request.Header.Get("X-I18n-Lang")
+	game := dst.NewGameController(room, worlds, roomSetting, lang)
+	clientMods, err := game.GetClientMods()
+	if err != nil {
+		logger.Logger.Error("获取客户端模组失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "get enabled mod fail"), "data": nil})
+		return
+	}
+
+	var mods []dst.ClientMod
+	for _, mod := range clientMods {
+		if reqForm.All || mod.Required {
+			mods = append(mods, mod)
+		}
+	}
+
+	// 补充创意工坊的名称、预览图和大小
+	downloadedMods := make([]dst.DownloadedMod, len(mods))
+	for i := range mods {
+		downloadedMods[i] = mods[i].DownloadedMod
+	}
+	err = addDownloadedModInfo(&downloadedMods, lang)
+	if err != nil {
+		logger.Logger.Error("添加模组额外信息失败")
+	}
+	for i := range mods {
+		if downloadedMods[i].Name != "" {
+			mods[i].Name = downloadedMods[i].Name
+		}
+		mods[i].PreviewURL = downloadedMods[i].PreviewURL
+		mods[i].ServerSize = downloadedMods[i].ServerSize
+		mods[i].FileURL = downloadedMods[i].FileURL
+	}
+
+	markdown, text, ids := formatClientModList(room.GameName, mods, lang)
+
+	type Data struct {
+		Mods     []dst.ClientMod `json:"mods"`
+		Markdown string          `json:"markdown"`
+		Text     string          `json:"text"`
+		IDs      string          `json:"ids"`
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": Data{
+		Mods:     mods,
+		Markdown: markdown,
+		Text:     text,
+		IDs:      ids,
+	}})
+}
+
+func (h *Handler) downloadQueuePost(c *gin.Context) {
+	type ModForm struct {
+		ID      int    `json:"id"`
+		Name    string `json:"name"`
+		FileURL string `json:"file_url"`
+	}
+	type ReqForm struct {
+		RoomID int       `json:"roomID"`
+		Mods   []ModForm `json:"mods"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil || len(reqForm.Mods) == 0 {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if _, err := h.roomDao.GetRoomByID(reqForm.RoomID); err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	var jobs []*models.ModDownloadJob
+	for _, mod := range reqForm.Mods {
+		job, err := scheduler.EnqueueModDownload(reqForm.RoomID, mod.ID, mod.Name, mod.FileURL)
+		if err != nil {
+			logger.Logger.Error("添加模组下载任务失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "enqueue fail"), "data": jobs})
+			return
+		}
+		jobs = append(jobs, job)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "enqueue success"), "data": jobs})
+}
+
+func (h *Handler) downloadJobsGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int `form:"roomID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	jobs, err := h.modDownloadJobDao.GetJobsByRoomID(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": jobs})
+}
+
+func (h *Handler) downloadJobsDelete(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int `form:"roomID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if err := h.modDownloadJobDao.DeleteFinishedJobs(reqForm.RoomID); err != nil {
+		logger.Logger.Error("删除模组下载任务失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "delete success"), "data": nil})
+}
+
+func (h *Handler) downloadCancelPost(c *gin.Context) {
+	type ReqForm struct {
+		ID int `json:"id"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	job, err := scheduler.CancelModDownload(reqForm.ID)
+	if err != nil {
+		if errors.Is(err, scheduler.ErrModDownloadFinished) {
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "download job finished"), "data": job})
+			return
+		}
+		logger.Logger.Error("取消模组下载任务失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "cancel fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "cancel success"), "data": job})
+}
+
+// downloadWsGet 推送房间的模组下载任务状态，连接后先推送当前所有任务
+func (h *Handler) downloadWsGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int    `form:"roomID"`
+		Token  string `form:"token"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	claims, err := utils.ValidateJWT(reqForm.Token, []byte(db.JwtSecret))
+	if err != nil {
+		logger.Logger.Warn("token验证失败", "ip", c.ClientIP())
+		c.JSON(http.StatusOK, gin.H{"code": 420, "message": message.Get(c, "token fail"), "data": nil})
+		return
+	}
+
+	// 只推送有权限的房间的下载进度
+	if !h.hasRoomPermission(claims, reqForm.RoomID) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	err = h.downloadWS.HandleRequestWithKeys(c.Writer, c.Request, map[string]any{"roomID": reqForm.RoomID})
+	if err != nil {
+		logger.Logger.Error("WebSocket升级失败", "err", err)
+	}
+}
+
+func modStoreUsageGet(c *gin.Context) {
+	report, err := dst.GetModStoreUsage()
+	if err != nil {
+		logger.Logger.Error("获取模组磁盘占用失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "get mod store usage fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": report})
+}
+
+func modStorePrunePost(c *gin.Context) {
+	freed, err := dst.PruneModStore()
+	if err != nil {
+		logger.Logger.Error("清理共享模组失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "prune mod store fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "prune mod store success"), "data": gin.H{"freed": freed}})
+}
+
+func modStoreMigratePost(c *gin.Context) {
+	count, err := dst.MigrateModStore()
+	if err != nil {
+		logger.Logger.Error("迁移共享模组失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "migrate mod store fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "migrate mod store success"), "data": gin.H{"count": count}})
+}
+
+func (h *Handler) profileGet(c *gin.Context) {
+	profiles, err := h.modProfileDao.GetModProfiles()
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": profiles})
+}
+
+// profilePost 新建模组配置方案，content为空时从房间或世界的当前配置生成
+func (h *Handler) profilePost(c *gin.Context) {
+	type ReqForm struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Content     string `json:"content"`
+		RoomID      int    `json:"roomID"`
+		WorldID     int    `json:"worldID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil || reqForm.Name == "" {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	lang := c.Request.Header.Get("X-I18n-Lang")
+	content := reqForm.Content
+	if content == "" {
+		room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+		if err != nil {
+			logger.Logger.Error("获取基本信息失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		game := dst.NewGameController(room, worlds, roomSetting, lang)
+		content, err = game.GetModOverrides(reqForm.WorldID)
+		if err != nil {
+			logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+			c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+			return
+		}
+	}
+
+	content, err := dst.FormatModOverrides(content, lang)
+	if err != nil {
+		logger.Logger.Info("模组配置解析失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "mod profile parse fail"), "data": nil})
+		return
+	}
+
+	username, _ := c.Get("username")
+	now := utils.GetTimestamp()
+	profile := models.ModProfile{
+		Name:        reqForm.Name,
+		Description: reqForm.Description,
+		Content:     content,
+		CreatedBy:   username.(string),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err = h.modProfileDao.Create(&profile); err != nil {
+		logger.Logger.Error("创建模组配置方案失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "mod profile exists"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "create success"), "data": profile})
+}
+
+func (h *Handler) profilePut(c *gin.Context) {
+	type ReqForm struct {
+		ID          int    `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Content     string `json:"content"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil || reqForm.Name == "" {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	profile, err := h.modProfileDao.GetModProfileByID(reqForm.ID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	if reqForm.Content != "" {
+		content, err := dst.FormatModOverrides(reqForm.Content, c.Request.Header.Get("X-I18n-Lang"))
+		if err != nil {
+			logger.Logger.Info("模组配置解析失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "mod profile parse fail"), "data": nil})
+			return
+		}
+		profile.Content = content
+	}
+	profile.Name = reqForm.Name
+	profile.Description = reqForm.Description
+	profile.UpdatedAt = utils.GetTimestamp()
+
+	if err = h.modProfileDao.Update(profile); err != nil {
+		logger.Logger.Error("更新模组配置方案失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "mod profile exists"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "update success"), "data": profile})
+}
+
+func (h *Handler) profileDelete(c *gin.Context) {
+	type ReqForm struct {
+		ID int `form:"id"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if err := h.modProfileDao.Delete(&models.ModProfile{ID: reqForm.ID}); err != nil {
+		logger.Logger.Error("删除模组配置方案失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "delete success"), "data": nil})
+}
+
+// profileApplyPost 将模组配置方案应用到房间或指定世界，download为true时将未下载的模组加入下载队列
+func (h *Handler) profileApplyPost(c *gin.Context) {
+	type ReqForm struct {
+		ID       int   `json:"id"`
+		RoomID   int   `json:"roomID"`
+		WorldIDs []int `json:"worldIDs"`
+		Download bool  `json:"download"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	profile, err := h.modProfileDao.GetModProfileByID(reqForm.ID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	lang := c.Request.Header.Get("X-I18n-Lang")
+	game := dst.NewGameController(room, worlds, roomSetting, lang)
+	if err = game.ApplyModOverrides(profile.Content, reqForm.WorldIDs); err != nil {
+		logger.Logger.Error("应用模组配置方案失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "mod profile apply fail"), "data": nil})
+		return
+	}
+
+	if err = h.roomDao.UpdateRoom(room); err != nil {
+		logger.Logger.Error("更新房间失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if err = h.worldDao.UpdateWorlds(worlds); err != nil {
+		logger.Logger.Error("更新世界失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	if reqForm.Download {
+		mods, _ := dst.ParseModOverrides(profile.Content, lang)
+		var missing []int
+		for key := range mods {
+			id, ok := dst.ParseWorkshopKey(key)
+			if !ok {
+				continue
+			}
+			if _, _, err := game.GetModPath(id); err != nil {
+				missing = append(missing, id)
+			}
+		}
+		items, err := dst.GetWorkshopItems(missing, lang)
+		if err != nil {
+			logger.Logger.Error("获取模组信息失败", "err", err)
+		}
+		for _, id := range missing {
+			if _, err = scheduler.EnqueueModDownload(reqForm.RoomID, id, items[id].Title, items[id].FileURL); err != nil {
+				logger.Logger.Error("添加模组下载任务失败", "err", err)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "mod profile apply success"), "data": nil})
+}
+
+// profileDiffGet 对比两个模组配置方案，targetID为0时与房间或世界的当前配置对比
+func (h *Handler) profileDiffGet(c *gin.Context) {
+	type ReqForm struct {
+		ID       int `form:"id"`
+		TargetID int `form:"targetID"`
+		RoomID   int `form:"roomID"`
+		WorldID  int `form:"worldID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	lang := c.Request.Header.Get("X-I18n-Lang")
+
+	profile, err := h.modProfileDao.GetModProfileByID(reqForm.ID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	var targetContent string
+	if reqForm.TargetID != 0 {
+		target, err := h.modProfileDao.GetModProfileByID(reqForm.TargetID)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		targetContent = target.Content
+	} else {
+		room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+		if err != nil {
+			logger.Logger.Error("获取基本信息失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		game := dst.NewGameController(room, worlds, roomSetting, lang)
+		targetContent, err = game.GetModOverrides(reqForm.WorldID)
+		if err != nil {
+			logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+			c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+			return
+		}
+	}
+
+	oldMods, err := dst.ParseModOverrides(profile.Content, lang)
+	if err == nil {
+		var newMods dst.ModORCollection
+		newMods, err = dst.ParseModOverrides(targetContent, lang)
+		if err == nil {
+			c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": dst.DiffModCollections(oldMods, newMods)})
+			return
+		}
+	}
+
+	logger.Logger.Error("模组配置解析失败", "err", err)
+	c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "mod profile parse fail"), "data": nil})
+}
+
+func (h *Handler) profileExportGet(c *gin.Context) {
+	type ReqForm struct {
+		ID int `form:"id"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	profile, err := h.modProfileDao.GetModProfileByID(reqForm.ID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="modoverrides.lua"`)
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(profile.Content))
+}
+
+// localUploadPost 上传本地(私有)模组zip，同一目录名重复上传会保存为新版本
+func (h *Handler) localUploadPost(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if file.Size > dst.LocalModMaxUploadSize {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "local mod too large"), "data": nil})
+		return
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(file.Filename), filepath.Ext(file.Filename))
+	}
+	if !dst.ValidLocalModName(name) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "local mod name invalid"), "data": nil})
+		return
+	}
+
+	uploadPath := fmt.Sprintf("%s/upload/%d", utils.DmpFiles, utils.GetTimestamp())
+	if err = utils.EnsureDirExists(uploadPath); err != nil {
+		logger.Logger.Error("创建上传目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "local mod upload fail"), "data": nil})
+		return
+	}
+	defer func() {
+		if err := utils.RemoveDir(uploadPath); err != nil {
+			logger.Logger.Error("清理上传文件失败", "err", err)
+		}
+	}()
+
+	savePath := fmt.Sprintf("%s/mod.zip", uploadPath)
+	if err = c.SaveUploadedFile(file, savePath); err != nil {
+		logger.Logger.Error("文件保存失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "local mod upload fail"), "data": nil})
+		return
+	}
+
+	info, err := dst.InstallLocalMod(savePath, name, c.Request.Header.Get("X-I18n-Lang"))
+	if err != nil {
+		logger.Logger.Error("安装本地模组失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "local mod invalid"), "data": err.Error()})
+		return
+	}
+
+	username, _ := c.Get("username")
+	localMod, err := h.localModDao.GetLocalModVersion(info.Name, info.Version)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	localMod.Name = info.Name
+	localMod.Version = info.Version
+	localMod.Title = info.Title
+	localMod.Size = info.Size
+	localMod.UploadedBy = username.(string)
+	localMod.CreatedAt = utils.GetTimestamp()
+	if localMod.ID == 0 {
+		err = h.localModDao.Create(localMod)
+	} else {
+		err = h.localModDao.Update(localMod)
+	}
+	if err == nil {
+		err = h.localModDao.SetCurrentVersion(localMod.Name, localMod.ID)
+	}
+	if err != nil {
+		logger.Logger.Error("写入数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	localMod.Current = true
+
+	if err = dst.SaveLocalModArchive(savePath, localMod.Name, localMod.ID); err != nil {
+		logger.Logger.Error("保存本地模组历史版本失败", "err", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "local mod upload success"), "data": localMod})
+}
+
+// localModsGet 获取本地模组的所有版本，传入roomID时返回已启用的本地模组
+func (h *Handler) localModsGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID  int `form:"roomID"`
+		WorldID int `form:"worldID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	localMods, err := h.localModDao.GetLocalMods()
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	enabled := []string{}
+	if reqForm.RoomID != 0 {
+		room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+		if err != nil {
+			logger.Logger.Error("获取基本信息失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+		enabled, err = game.GetEnabledLocalMods(reqForm.WorldID)
+		if err != nil {
+			logger.Logger.Error("获取已启用的本地模组失败", "err", err)
+		}
+	}
+
+	type Data struct {
+		Mods    []models.LocalMod `json:"mods"`
+		Enabled []string          `json:"enabled"`
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": Data{Mods: *localMods, Enabled: enabled}})
+}
+
+// localActivatePost 切换本地模组到指定的历史版本
+func (h *Handler) localActivatePost(c *gin.Context) {
+	type ReqForm struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	localMod, err := h.localModDao.GetLocalModVersion(reqForm.Name, reqForm.Version)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if localMod.ID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if _, err = dst.ActivateLocalModVersion(localMod.Name, localMod.Version, localMod.ID, c.Request.Header.Get("X-I18n-Lang")); err != nil {
+		logger.Logger.Error("切换本地模组版本失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "local mod invalid"), "data": err.Error()})
+		return
+	}
+
+	if err = h.localModDao.SetCurrentVersion(localMod.Name, localMod.ID); err != nil {
+		logger.Logger.Error("写入数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "local mod activate success"), "data": nil})
+}
+
+// localDelete 删除本地模组及所有历史版本，已启用该模组的房间需要先禁用
+func (h *Handler) localDelete(c *gin.Context) {
+	type ReqForm struct {
+		Name string `form:"name"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	roomsBasic, err := h.roomDao.GetRoomBasic()
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	inUse := []string{}
+	for _, rbs := range *roomsBasic {
+		room, worlds, roomSetting, err := h.fetchGameInfo(rbs.RoomID)
+		if err != nil {
+			logger.Logger.Error("获取基本信息失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+		enabled, err := game.IsLocalModEnabled(reqForm.Name)
+		if err != nil {
+			logger.Logger.Error("获取已启用的本地模组失败", "err", err, "room", rbs.RoomID)
+			continue
+		}
+		if enabled {
+			inUse = append(inUse, rbs.RoomName)
+		}
+	}
+	if len(inUse) != 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "local mod in use"), "data": inUse})
+		return
+	}
+
+	if err := dst.RemoveLocalMod(reqForm.Name); err != nil {
+		logger.Logger.Error("删除本地模组失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "delete fail"), "data": nil})
+		return
+	}
+
+	if err := h.localModDao.DeleteLocalModsByName(reqForm.Name); err != nil {
+		logger.Logger.Error("写入数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "delete success"), "data": nil})
+}
+
+// localEnablePost 启用或禁用本地模组
+func (h *Handler) localEnablePost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID  int    `json:"roomID"`
+		Name    string `json:"name"`
+		Disable bool   `json:"disable"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	if reqForm.Disable {
+		err = game.LocalModDisable(reqForm.Name)
+	} else {
+		err = game.LocalModEnable(reqForm.Name)
+	}
+	if err != nil {
+		logger.Logger.Error("修改本地模组启用状态失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "local mod enable fail"), "data": nil})
+		return
+	}
+
+	err = h.roomDao.UpdateRoom(room)
+	if err != nil {
+		logger.Logger.Error("更新房间失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	err = h.worldDao.UpdateWorlds(worlds)
+	if err != nil {
+		logger.Logger.Error("更新房间失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "local mod enable success"), "data": nil})
+}
@@ -30,11 +30,44 @@ func NewExtendedI18n() *ExtendedI18n {
 	i.ZH["mod configuration values error"] = "获取模组配置失败"
 	i.ZH["modify mod configuration values error"] = "修改模组配置失败"
 	i.ZH["modify mod configuration values success"] = "修改模组配置成功"
+	i.ZH["mod configuration values invalid"] = "模组配置值不在可选范围内"
+	i.ZH["reset mod configuration fail"] = "重置模组配置失败"
+	i.ZH["reset mod configuration success"] = "重置模组配置成功"
+	i.ZH["local mod name invalid"] = "模组目录名只能包含字母、数字和_.-，且不能以workshop-开头"
+	i.ZH["local mod upload fail"] = "本地模组上传失败"
+	i.ZH["local mod invalid"] = "本地模组校验失败，请检查modinfo.lua"
+	i.ZH["local mod upload success"] = "本地模组上传成功"
+	i.ZH["local mod activate success"] = "本地模组版本切换成功"
+	i.ZH["local mod enable fail"] = "修改本地模组启用状态失败"
+	i.ZH["local mod enable success"] = "修改本地模组启用状态成功"
+	i.ZH["local mod too large"] = "本地模组zip不能超过256MB"
+	i.ZH["local mod in use"] = "以下房间已启用该本地模组，请先禁用"
 	i.ZH["mod enable fail"] = "模组启用失败"
 	i.ZH["mod enable success"] = "模组启用成功"
 	i.ZH["mod disable fail"] = "模组禁用失败"
 	i.ZH["mod disable success"] = "模组禁用成功"
 	i.ZH["get enabled mod fail"] = "获取启用模组失败"
+	i.ZH["check mod update fail"] = "检查模组更新失败"
+	i.ZH["no outdated mod"] = "没有需要更新的模组"
+	i.ZH["mod validate fail"] = "模组校验失败"
+	i.ZH["mod importing"] = "模组正在导入中，请稍后再试"
+	i.ZH["get collection fail"] = "获取创意工坊合集失败"
+	i.ZH["mod import started"] = "开始导入模组"
+	i.ZH["export manifest fail"] = "导出模组清单失败"
+	i.ZH["enqueue success"] = "已加入下载队列"
+	i.ZH["enqueue fail"] = "加入下载队列失败"
+	i.ZH["cancel success"] = "取消成功"
+	i.ZH["cancel fail"] = "取消失败"
+	i.ZH["download job finished"] = "下载任务已结束"
+	i.ZH["get mod store usage fail"] = "获取模组磁盘占用失败"
+	i.ZH["prune mod store fail"] = "清理共享模组失败"
+	i.ZH["prune mod store success"] = "清理共享模组成功"
+	i.ZH["migrate mod store fail"] = "迁移共享模组失败"
+	i.ZH["migrate mod store success"] = "迁移共享模组成功"
+	i.ZH["mod profile parse fail"] = "模组配置解析失败"
+	i.ZH["mod profile exists"] = "模组配置方案名称已存在"
+	i.ZH["mod profile apply fail"] = "应用模组配置方案失败"
+	i.ZH["mod profile apply success"] = "应用模组配置方案成功"
 
 	i.EN["downloading"] = "Downloading Mod"
 	i.EN["update completed"] = "Update Completed"
@@ -42,11 +75,44 @@ func NewExtendedI18n() *ExtendedI18n {
 	i.EN["mod configuration values error"] = "Generate Mod Configurations Error"
 	i.EN["modify mod configuration values error"] = "Modify Mod Configuration Error"
 	i.EN["modify mod configuration values success"] = "Modify Mod Configuration Success"
+	i.EN["mod configuration values invalid"] = "Mod Configuration Values Are Not Valid Options"
+	i.EN["reset mod configuration fail"] = "Reset Mod Configuration Fail"
+	i.EN["reset mod configuration success"] = "Reset Mod Configuration Success"
+	i.EN["local mod name invalid"] = "Mod directory name may only contain letters, digits and _.- and must not start with workshop-"
+	i.EN["local mod upload fail"] = "Local Mod Upload Fail"
+	i.EN["local mod invalid"] = "Local mod validation failed, please check modinfo.lua"
+	i.EN["local mod upload success"] = "Local Mod Upload Success"
+	i.EN["local mod activate success"] = "Local Mod Version Switched"
+	i.EN["local mod enable fail"] = "Change Local Mod State Fail"
+	i.EN["local mod enable success"] = "Change Local Mod State Success"
+	i.EN["local mod too large"] = "Local mod zip must not exceed 256MB"
+	i.EN["local mod in use"] = "The following rooms have this local mod enabled, please disable it first"
 	i.EN["mod enable fail"] = "Mod Enable Fail"
 	i.EN["mod enable success"] = "Mod Enable Success"
 	i.EN["mod disable fail"] = "Mod Disable Fail"
 	i.EN["mod disable success"] = "Mod Disable Success"
 	i.EN["get enabled mod fail"] = "Get Enabled Mods Fail"
+	i.EN["check mod update fail"] = "Check Mod Update Fail"
+	i.EN["no outdated mod"] = "No Outdated Mods"
+	i.EN["mod validate fail"] = "Mod Validate Fail"
+	i.EN["mod importing"] = "Mods Are Being Imported, Please Try Again Later"
+	i.EN["get collection fail"] = "Get Workshop Collection Fail"
+	i.EN["mod import started"] = "Mod Import Started"
+	i.EN["export manifest fail"] = "Export Mod Manifest Fail"
+	i.EN["enqueue success"] = "Added To Download Queue"
+	i.EN["enqueue fail"] = "Add To Download Queue Fail"
+	i.EN["cancel success"] = "Cancel Success"
+	i.EN["cancel fail"] = "Cancel Fail"
+	i.EN["download job finished"] = "Download Job Already Finished"
+	i.EN["get mod store usage fail"] = "Get Mod Disk Usage Fail"
+	i.EN["prune mod store fail"] = "Prune Shared Mods Fail"
+	i.EN["prune mod store success"] = "Prune Shared Mods Success"
+	i.EN["migrate mod store fail"] = "Migrate Shared Mods Fail"
+	i.EN["migrate mod store success"] = "Migrate Shared Mods Success"
+	i.EN["mod profile parse fail"] = "Parse Mod Configuration Fail"
+	i.EN["mod profile exists"] = "Mod Profile Name Already Exists"
+	i.EN["mod profile apply fail"] = "Apply Mod Profile Fail"
+	i.EN["mod profile apply success"] = "Apply Mod Profile Success"
 
 	return i
 }
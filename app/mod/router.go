@@ -21,8 +21,38 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 			mod.GET("/setting/mod_config_struct", h.settingModConfigStructGet)
 			mod.GET("/setting/mod_config_value", h.settingModConfigValueGet)
 			mod.PUT("/setting/mod_config_value", h.settingModConfigValuePut)
+			mod.POST("/setting/mod_config_value/reset", h.settingModConfigResetPost)
+			mod.GET("/setting/mod_config_value/diff", h.settingModConfigDiffGet)
 			mod.GET("/setting/enabled", h.getEnabledModsGet)
 			mod.POST("/delete", h.deletePost)
+			mod.GET("/outdated", h.outdatedGet)
+			mod.POST("/update", h.updatePost)
+			mod.GET("/validate", h.validateGet)
+			mod.POST("/collection/import", h.collectionImportPost)
+			mod.GET("/collection/import/status", h.collectionImportStatusGet)
+			mod.GET("/collection/export", h.collectionExportGet)
+			mod.GET("/client/manifest", h.clientManifestGet)
+			mod.POST("/local/upload", middleware.AdminOnly(), h.localUploadPost)
+			mod.GET("/local", h.localModsGet)
+			mod.POST("/local/activate", middleware.AdminOnly(), h.localActivatePost)
+			mod.DELETE("/local", middleware.AdminOnly(), h.localDelete)
+			mod.POST("/local/enable", h.localEnablePost)
+			mod.POST("/download/queue", h.downloadQueuePost)
+			mod.GET("/download/jobs", h.downloadJobsGet)
+			mod.DELETE("/download/jobs", h.downloadJobsDelete)
+			mod.POST("/download/cancel", h.downloadCancelPost)
+			mod.GET("/store/usage", middleware.AdminOnly(), modStoreUsageGet)
+			mod.POST("/store/prune", middleware.AdminOnly(), modStorePrunePost)
+			mod.POST("/store/migrate", middleware.AdminOnly(), modStoreMigratePost)
+			mod.GET("/profile", h.profileGet)
+			mod.POST("/profile", h.profilePost)
+			mod.PUT("/profile", h.profilePut)
+			mod.DELETE("/profile", h.profileDelete)
+			mod.POST("/profile/apply", h.profileApplyPost)
+			mod.GET("/profile/diff", h.profileDiffGet)
+			mod.GET("/profile/export", h.profileExportGet)
 		}
+		// 浏览器的WebSocket无法设置请求头，token通过参数传递
+		v.GET("mod/download/ws", h.downloadWsGet)
 	}
 }
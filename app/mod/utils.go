@@ -5,27 +5,101 @@ import (
 	"dst-management-platform-api/database/models"
 	"dst-management-platform-api/dst"
 	"dst-management-platform-api/logger"
+	"dst-management-platform-api/scheduler"
 	"dst-management-platform-api/utils"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/olahol/melody"
 )
 
 type Handler struct {
-	roomDao        *dao.RoomDAO
-	worldDao       *dao.WorldDAO
-	roomSettingDao *dao.RoomSettingDAO
+	userDao           *dao.UserDAO
+	roomDao           *dao.RoomDAO
+	worldDao          *dao.WorldDAO
+	roomSettingDao    *dao.RoomSettingDAO
+	modVersionDao     *dao.ModVersionDAO
+	modDownloadJobDao *dao.ModDownloadJobDAO
+	modProfileDao     *dao.ModProfileDAO
+	localModDao       *dao.LocalModDAO
+	downloadWS        *melody.Melody
+}
+
+func NewHandler(userDao *dao.UserDAO, roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO, modVersionDao *dao.ModVersionDAO, modDownloadJobDao *dao.ModDownloadJobDAO, modProfileDao *dao.ModProfileDAO, localModDao *dao.LocalModDAO) *Handler {
+	h := &Handler{
+		userDao:           userDao,
+		roomDao:           roomDao,
+		worldDao:          worldDao,
+		roomSettingDao:    roomSettingDao,
+		modVersionDao:     modVersionDao,
+		modDownloadJobDao: modDownloadJobDao,
+		modProfileDao:     modProfileDao,
+		localModDao:       localModDao,
+		downloadWS:        melody.New(),
+	}
+	h.setupDownloadWS()
+
+	return h
 }
 
-func NewHandler(roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO) *Handler {
-	return &Handler{
-		roomDao:        roomDao,
-		worldDao:       worldDao,
-		roomSettingDao: roomSettingDao,
+// hasRoomPermission WebSocket连接不经过TokenCheck中间件，根据token中的用户判断房间权限
+func (h *Handler) hasRoomPermission(claims *utils.Claims, roomID int) bool {
+	if claims.Role == "admin" {
+		return true
+	}
+
+	dbUser, err := h.userDao.GetUserByUsername(claims.Username)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		return false
+	}
+	for _, id := range strings.Split(dbUser.Rooms, ",") {
+		if id == strconv.Itoa(roomID) {
+			return true
+		}
 	}
+
+	return false
+}
+
+// setupDownloadWS 将模组下载队列的状态变化推送给订阅了对应房间的WebSocket连接
+func (h *Handler) setupDownloadWS() {
+	h.downloadWS.HandleConnect(func(s *melody.Session) {
+		roomID := s.MustGet("roomID").(int)
+		jobs, err := h.modDownloadJobDao.GetJobsByRoomID(roomID)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			return
+		}
+		for _, job := range *jobs {
+			data, _ := json.Marshal(job)
+			if err = s.Write(data); err != nil {
+				return
+			}
+		}
+	})
+
+	go func() {
+		updates, _ := scheduler.SubscribeModDownload()
+		for job := range updates {
+			data, err := json.Marshal(job)
+			if err != nil {
+				continue
+			}
+			err = h.downloadWS.BroadcastFilter(data, func(s *melody.Session) bool {
+				roomID, ok := s.Get("roomID")
+				return ok && roomID == job.RoomID
+			})
+			if err != nil {
+				logger.Logger.Warn("推送模组下载状态失败", "err", err)
+			}
+		}
+	}()
 }
 
 type JSONResponse struct {
@@ -276,6 +350,51 @@ func addDownloadedModInfo(mods *[]dst.DownloadedMod, lang string) error {
 	return nil
 }
 
+// formatClientModList 生成可以直接分享给玩家的Markdown、纯文本和创意工坊合集ID列表
+func formatClientModList(roomName string, mods []dst.ClientMod, lang string) (string, string, string) {
+	title, versionLabel, sizeLabel := "Required mods for %s", "version", "size"
+	if lang == "zh" {
+		title, versionLabel, sizeLabel = "%s 需要订阅的模组", "版本", "大小"
+	}
+
+	var (
+		markdown strings.Builder
+		text     strings.Builder
+		ids      []string
+	)
+	markdown.WriteString(fmt.Sprintf("## "+title+"\n\n", roomName))
+	text.WriteString(fmt.Sprintf(title+"\n\n", roomName))
+
+	for index, mod := range mods {
+		name := mod.Name
+		if name == "" {
+			name = strconv.Itoa(mod.ID)
+		}
+
+		var extras []string
+		if mod.Version != "" {
+			extras = append(extras, fmt.Sprintf("%s %s", versionLabel, mod.Version))
+		}
+		size := mod.ServerSize
+		if size == "" || size == "0" {
+			size = mod.LocalSize
+		}
+		if bytes, err := strconv.ParseInt(size, 10, 64); err == nil && bytes > 0 {
+			extras = append(extras, fmt.Sprintf("%s %.2f MB", sizeLabel, float64(bytes)/1024/1024))
+		}
+		extra := ""
+		if len(extras) != 0 {
+			extra = " (" + strings.Join(extras, ", ") + ")"
+		}
+
+		markdown.WriteString(fmt.Sprintf("%d. [%s](%s)%s\n", index+1, name, mod.WorkshopURL, extra))
+		text.WriteString(fmt.Sprintf("%d. %s%s\n   %s\n", index+1, name, extra, mod.WorkshopURL))
+		ids = append(ids, strconv.Itoa(mod.ID))
+	}
+
+	return markdown.String(), text.String(), strings.Join(ids, ",")
+}
+
 func (h *Handler) fetchGameInfo(roomID int) (*models.Room, *[]models.World, *models.RoomSetting, error) {
 	room, err := h.roomDao.GetRoomByID(roomID)
 	if err != nil {
@@ -379,6 +379,27 @@ func (h *Handler) globalSettingsPost(c *gin.Context) {
 		}
 	}
 
+	if dbGlobalSettings.SteamProfileEnable != reqForm.SteamProfileEnable {
+		needUpdateDB = true
+		if reqForm.SteamProfileEnable {
+			err = scheduler.UpdateJob(&scheduler.JobConfig{
+				Name:     "steamProfileGet",
+				Func:     scheduler.SteamProfileGet,
+				Args:     []any{reqForm.SteamProfileEnable},
+				TimeType: scheduler.HourType,
+				Interval: 1,
+				DayAt:    "",
+			})
+			if err != nil {
+				logger.Logger.Error("定时任务设置失败", "err", err, "name", "steamProfileGet")
+				c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "update fail"), "data": nil})
+				return
+			}
+		} else {
+			scheduler.DeleteJob("steamProfileGet")
+		}
+	}
+
 	if needUpdateDB {
 		err = h.globalSettingDao.UpdateGlobalSetting(&reqForm)
 		if err != nil {
@@ -451,3 +472,193 @@ func screenKillPost(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "kill screen success"), "data": nil})
 }
+
+// playerListGet 获取平台级名单
+func (h *Handler) playerListGet(c *gin.Context) {
+	type ReqForm struct {
+		ListType string `json:"listType" form:"listType"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	lists, err := h.globalPlayerListDao.GetGlobalPlayerLists(reqForm.ListType)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": lists})
+}
+
+// playerListPost 添加平台级名单，rooms为空时对所有房间生效
+func (h *Handler) playerListPost(c *gin.Context) {
+	type ReqForm struct {
+		ListType      string   `json:"listType"`
+		UIDS          []string `json:"uids"`
+		Reason        string   `json:"reason"`
+		Rooms         []int    `json:"rooms"`
+		ExcludedRooms []int    `json:"excludedRooms"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !validListType(reqForm.ListType) || len(reqForm.UIDS) == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+	for _, uid := range reqForm.UIDS {
+		if !dst.ValidUID(uid) {
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "invalid uid"), "data": uid})
+			return
+		}
+	}
+
+	username, _ := c.Get("username")
+	now := utils.GetTimestamp()
+	var lists []models.GlobalPlayerList
+	for _, uid := range utils.RemoveDuplicates(reqForm.UIDS) {
+		list, err := h.globalPlayerListDao.GetGlobalPlayerListByUID(reqForm.ListType, uid)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		// 已存在的UID更新原因和生效范围
+		list.ListType = reqForm.ListType
+		list.UID = uid
+		list.Reason = reqForm.Reason
+		list.Rooms = joinRoomIDs(reqForm.Rooms)
+		list.ExcludedRooms = joinRoomIDs(reqForm.ExcludedRooms)
+		if list.ID == 0 {
+			list.CreatedBy = username.(string)
+			list.CreatedAt = now
+		}
+		if player, err := h.playerDao.GetPlayerByUID(uid); err == nil && player.Nickname != "" {
+			list.Nickname = player.Nickname
+		}
+		if err = h.globalPlayerListDao.UpdateGlobalPlayerList(list); err != nil {
+			logger.Logger.Error("更新数据库失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		lists = append(lists, *list)
+	}
+
+	var banUIDs []string
+	if reqForm.ListType == "blocklist" {
+		banUIDs = reqForm.UIDS
+	}
+	failedRooms := scheduler.ApplyGlobalPlayerLists(nil, banUIDs)
+	if len(failedRooms) != 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "sync player list fail"), "data": failedRooms})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "sync player list success"), "data": lists})
+}
+
+// playerListPut 修改平台级名单的生效房间
+func (h *Handler) playerListPut(c *gin.Context) {
+	type ReqForm struct {
+		ID            int    `json:"id"`
+		Reason        string `json:"reason"`
+		Rooms         []int  `json:"rooms"`
+		ExcludedRooms []int  `json:"excludedRooms"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.ID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	list, err := h.globalPlayerListDao.GetGlobalPlayerListByID(reqForm.ID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if list.ID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "player list not found"), "data": nil})
+		return
+	}
+
+	list.Reason = reqForm.Reason
+	list.Rooms = joinRoomIDs(reqForm.Rooms)
+	list.ExcludedRooms = joinRoomIDs(reqForm.ExcludedRooms)
+	if err = h.globalPlayerListDao.UpdateGlobalPlayerList(list); err != nil {
+		logger.Logger.Error("更新数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	var banUIDs []string
+	if list.ListType == "blocklist" {
+		banUIDs = []string{list.UID}
+	}
+	failedRooms := scheduler.ApplyGlobalPlayerLists(nil, banUIDs)
+	if len(failedRooms) != 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "sync player list fail"), "data": failedRooms})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "sync player list success"), "data": list})
+}
+
+// playerListDelete 删除平台级名单
+func (h *Handler) playerListDelete(c *gin.Context) {
+	type ReqForm struct {
+		ID int `json:"id" form:"id"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.ID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	list, err := h.globalPlayerListDao.GetGlobalPlayerListByID(reqForm.ID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if list.ID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "player list not found"), "data": nil})
+		return
+	}
+
+	if err = h.globalPlayerListDao.Delete(list); err != nil {
+		logger.Logger.Error("更新数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	// 游戏内的封禁在内存中，重启世界后才会完全解除
+	failedRooms := scheduler.ApplyGlobalPlayerLists(nil, nil)
+	if len(failedRooms) != 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "sync player list fail"), "data": failedRooms})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "sync player list success"), "data": nil})
+}
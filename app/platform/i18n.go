@@ -30,11 +30,19 @@ func NewExtendedI18n() *ExtendedI18n {
 	i.ZH["get screens fail"] = "获取Screens失败"
 	i.ZH["kill screen fail"] = "关闭Screens失败"
 	i.ZH["kill screen success"] = "关闭Screens成功"
+	i.ZH["invalid uid"] = "UID格式错误"
+	i.ZH["player list not found"] = "名单记录不存在"
+	i.ZH["sync player list fail"] = "部分房间名单同步失败"
+	i.ZH["sync player list success"] = "名单已同步到所有房间"
 
 	i.EN["get os info fail"] = "Get OS Info Fail"
 	i.EN["get screens fail"] = "Get Screens Fail"
 	i.EN["kill screen fail"] = "Kill Screens Fail"
 	i.EN["kill screen success"] = "Kill Screens Success"
+	i.EN["invalid uid"] = "Invalid UID"
+	i.EN["player list not found"] = "Player List Entry Not Found"
+	i.EN["sync player list fail"] = "Sync Player List Fail For Some Rooms"
+	i.EN["sync player list success"] = "Player List Synced To All Rooms"
 
 	return i
 }
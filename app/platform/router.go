@@ -21,6 +21,10 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 			platform.POST("/global_settings", middleware.TokenCheck(), middleware.AdminOnly(), h.globalSettingsPost)
 			platform.GET("/screen/running", middleware.TokenCheck(), middleware.AdminOnly(), h.screenRunningGet)
 			platform.POST("/screen/kill", middleware.TokenCheck(), middleware.AdminOnly(), screenKillPost)
+			platform.GET("/player_list", middleware.TokenCheck(), middleware.AdminOnly(), h.playerListGet)
+			platform.POST("/player_list", middleware.TokenCheck(), middleware.AdminOnly(), h.playerListPost)
+			platform.PUT("/player_list", middleware.TokenCheck(), middleware.AdminOnly(), h.playerListPut)
+			platform.DELETE("/player_list", middleware.TokenCheck(), middleware.AdminOnly(), h.playerListDelete)
 		}
 	}
 }
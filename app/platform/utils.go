@@ -5,6 +5,8 @@ import (
 	"dst-management-platform-api/database/models"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/host"
@@ -13,24 +15,28 @@ import (
 )
 
 type Handler struct {
-	userDao          *dao.UserDAO
-	roomDao          *dao.RoomDAO
-	worldDao         *dao.WorldDAO
-	systemDao        *dao.SystemDAO
-	globalSettingDao *dao.GlobalSettingDAO
-	uidMapDao        *dao.UidMapDAO
-	roomSettingDao   *dao.RoomSettingDAO
+	userDao             *dao.UserDAO
+	roomDao             *dao.RoomDAO
+	worldDao            *dao.WorldDAO
+	systemDao           *dao.SystemDAO
+	globalSettingDao    *dao.GlobalSettingDAO
+	uidMapDao           *dao.UidMapDAO
+	roomSettingDao      *dao.RoomSettingDAO
+	playerDao           *dao.PlayerDAO
+	globalPlayerListDao *dao.GlobalPlayerListDAO
 }
 
-func NewHandler(userDao *dao.UserDAO, roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, systemDao *dao.SystemDAO, globalSettingDao *dao.GlobalSettingDAO, uidMapDao *dao.UidMapDAO, roomSettingDao *dao.RoomSettingDAO) *Handler {
+func NewHandler(userDao *dao.UserDAO, roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, systemDao *dao.SystemDAO, globalSettingDao *dao.GlobalSettingDAO, uidMapDao *dao.UidMapDAO, roomSettingDao *dao.RoomSettingDAO, playerDao *dao.PlayerDAO, globalPlayerListDao *dao.GlobalPlayerListDAO) *Handler {
 	return &Handler{
-		userDao:          userDao,
-		roomDao:          roomDao,
-		worldDao:         worldDao,
-		systemDao:        systemDao,
-		globalSettingDao: globalSettingDao,
-		uidMapDao:        uidMapDao,
-		roomSettingDao:   roomSettingDao,
+		userDao:             userDao,
+		roomDao:             roomDao,
+		worldDao:            worldDao,
+		systemDao:           systemDao,
+		globalSettingDao:    globalSettingDao,
+		uidMapDao:           uidMapDao,
+		roomSettingDao:      roomSettingDao,
+		playerDao:           playerDao,
+		globalPlayerListDao: globalPlayerListDao,
 	}
 }
 
@@ -116,3 +122,17 @@ func (h *Handler) fetchGameInfo(roomID int) (*models.Room, *[]models.World, *mod
 
 	return room, worlds, roomSetting, nil
 }
+
+func validListType(listType string) bool {
+	return listType == "adminlist" || listType == "whitelist" || listType == "blocklist"
+}
+
+// joinRoomIDs 将房间ID转换为逗号分隔的字符串
+func joinRoomIDs(roomIDs []int) string {
+	var ids []string
+	for _, roomID := range roomIDs {
+		ids = append(ids, strconv.Itoa(roomID))
+	}
+
+	return strings.Join(ids, ",")
+}
@@ -2,11 +2,19 @@ package player
 
 import (
 	"dst-management-platform-api/database/db"
+	"dst-management-platform-api/database/models"
 	"dst-management-platform-api/dst"
 	"dst-management-platform-api/logger"
+	"dst-management-platform-api/scheduler"
 	"dst-management-platform-api/utils"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -80,6 +88,24 @@ func (h *Handler) listPost(c *gin.Context) {
 
 	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
 
+	// 黑名单由封禁记录生成，添加为永久封禁
+	if reqForm.ListType == "blocklist" {
+		username, _ := c.Get("username")
+		if reqForm.ActionType == "add" {
+			_, err = scheduler.BanPlayers(reqForm.RoomID, reqForm.UIDS, "", username.(string), 0)
+		} else {
+			err = scheduler.LiftPlayerBans(reqForm.RoomID, reqForm.UIDS, username.(string))
+		}
+		if err != nil {
+			logger.Logger.Info("修改player list失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "update fail"), "data": nil})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "update success"), "data": nil})
+		return
+	}
+
 	if reqForm.ActionType == "add" {
 		err = game.AddPlayerList(reqForm.UIDS, reqForm.ListType)
 		if err != nil {
@@ -221,3 +247,1075 @@ func (h *Handler) statisticsPlayerCountGet(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": db.PlayersStatistic[reqForm.RoomID]})
 }
+
+// directoryGet 玩家目录搜索，不指定房间时只有管理员可以跨房间搜索
+func (h *Handler) directoryGet(c *gin.Context) {
+	type ReqForm struct {
+		Q        string `form:"q"`
+		Tag      string `form:"tag"`
+		RoomID   int    `form:"roomID"`
+		Page     int    `form:"page"`
+		PageSize int    `form:"pageSize"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasDirectoryPermission(c, reqForm.RoomID) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	players, err := h.playerDao.SearchPlayers(strings.TrimSpace(reqForm.Q), strings.TrimSpace(reqForm.Tag), reqForm.RoomID, reqForm.Page, reqForm.PageSize)
+	if err != nil {
+		logger.Logger.Error("查询玩家目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": players})
+}
+
+// directoryDetailGet 玩家详情，包括历史昵称和各房间的出现记录
+func (h *Handler) directoryDetailGet(c *gin.Context) {
+	type ReqForm struct {
+		UID    string `form:"uid"`
+		RoomID int    `form:"roomID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil || reqForm.UID == "" {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasDirectoryPermission(c, reqForm.RoomID) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	player, err := h.playerDao.GetPlayerByUID(reqForm.UID)
+	if err != nil {
+		logger.Logger.Error("查询玩家目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if player.UID == "" {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "player not found"), "data": nil})
+		return
+	}
+
+	nicknames, err := h.playerDao.GetNicknamesByUID(reqForm.UID)
+	if err != nil {
+		logger.Logger.Error("查询玩家目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	presences, err := h.playerDao.GetPresencesByUID(reqForm.UID)
+	if err != nil {
+		logger.Logger.Error("查询玩家目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	// 非管理员只能查看在自己房间出现过的玩家
+	if reqForm.RoomID != 0 {
+		found := false
+		for _, presence := range *presences {
+			if presence.RoomID == reqForm.RoomID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "player not found"), "data": nil})
+			return
+		}
+	}
+
+	// 出现记录只返回有权限的房间，历史昵称没有房间信息，只返回这些房间中使用过的昵称
+	roomIDs, err := h.permittedRoomIDs(c)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if roomIDs != nil {
+		visiblePresences := []models.PlayerPresence{}
+		visibleNames := make(map[string]bool)
+		for _, presence := range *presences {
+			if roomIDs[presence.RoomID] {
+				visiblePresences = append(visiblePresences, presence)
+				visibleNames[presence.Nickname] = true
+			}
+		}
+		visibleNicknames := []models.PlayerNickname{}
+		for _, nickname := range *nicknames {
+			if visibleNames[nickname.Nickname] {
+				visibleNicknames = append(visibleNicknames, nickname)
+			}
+		}
+		presences = &visiblePresences
+		nicknames = &visibleNicknames
+	}
+
+	type Data struct {
+		Player    *models.Player           `json:"player"`
+		Nicknames *[]models.PlayerNickname `json:"nicknames"`
+		Presences *[]models.PlayerPresence `json:"presences"`
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": Data{
+		Player:    player,
+		Nicknames: nicknames,
+		Presences: presences,
+	}})
+}
+
+// directorySteamPost 立即获取玩家的Steam资料，没有SteamID时先从服务器日志中查找
+func (h *Handler) directorySteamPost(c *gin.Context) {
+	type ReqForm struct {
+		UID    string `json:"uid"`
+		RoomID int    `json:"roomID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil || reqForm.UID == "" {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasDirectoryPermission(c, reqForm.RoomID) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	var globalSetting models.GlobalSetting
+	if err := h.globalSettingDao.GetGlobalSetting(&globalSetting); err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if !globalSetting.SteamProfileEnable {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "steam profile disabled"), "data": nil})
+		return
+	}
+
+	player, err := h.playerDao.GetPlayerByUID(reqForm.UID)
+	if err != nil {
+		logger.Logger.Error("查询玩家目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if player.UID == "" {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "player not found"), "data": nil})
+		return
+	}
+
+	presences, err := h.playerDao.GetPresencesByUID(reqForm.UID)
+	if err != nil {
+		logger.Logger.Error("查询玩家目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	// 非管理员只能获取在自己房间出现过的玩家
+	if reqForm.RoomID != 0 {
+		found := false
+		for _, presence := range *presences {
+			if presence.RoomID == reqForm.RoomID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "player not found"), "data": nil})
+			return
+		}
+	}
+
+	// 只读取指定房间的日志，未指定房间时读取玩家出现过的房间
+	if player.SteamID == "" {
+		if reqForm.RoomID != 0 {
+			scheduler.ResolveSteamIDsForRoom(reqForm.RoomID)
+		} else {
+			for _, presence := range *presences {
+				scheduler.ResolveSteamIDsForRoom(presence.RoomID)
+			}
+		}
+		player, err = h.playerDao.GetPlayerByUID(reqForm.UID)
+		if err != nil {
+			logger.Logger.Error("查询玩家目录失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		if player.SteamID == "" {
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "steam id not found"), "data": nil})
+			return
+		}
+	}
+
+	if err = scheduler.EnrichSteamProfiles(&[]models.Player{*player}); err != nil {
+		logger.Logger.Warn("获取Steam玩家资料失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "steam profile fail"), "data": nil})
+		return
+	}
+
+	player, err = h.playerDao.GetPlayerByUID(reqForm.UID)
+	if err != nil {
+		logger.Logger.Error("查询玩家目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": player})
+}
+
+// directoryPut 修改玩家的管理员备注和标签
+func (h *Handler) directoryPut(c *gin.Context) {
+	type ReqForm struct {
+		UID   string   `json:"uid"`
+		Notes string   `json:"notes"`
+		Tags  []string `json:"tags"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil || reqForm.UID == "" {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	player, err := h.playerDao.GetPlayerByUID(reqForm.UID)
+	if err != nil {
+		logger.Logger.Error("查询玩家目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if player.UID == "" {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "player not found"), "data": nil})
+		return
+	}
+
+	var tags []string
+	for _, tag := range reqForm.Tags {
+		tag = strings.TrimSpace(strings.ReplaceAll(tag, ",", ""))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	err = h.playerDao.UpdatePlayerNotes(reqForm.UID, reqForm.Notes, strings.Join(tags, ","))
+	if err != nil {
+		logger.Logger.Error("更新玩家目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "update success"), "data": nil})
+}
+
+// banGet 获取房间的封禁记录
+func (h *Handler) banGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int  `form:"roomID"`
+		All    bool `form:"all"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	bans, err := h.playerBanDao.GetBansByRoomID(reqForm.RoomID, reqForm.All)
+	if err != nil {
+		logger.Logger.Error("查询封禁记录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": bans})
+}
+
+// banPost 封禁玩家，duration为封禁时长，单位秒，0为永久封禁
+func (h *Handler) banPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID   int      `json:"roomID"`
+		UIDS     []string `json:"uids"`
+		Reason   string   `json:"reason"`
+		Duration int64    `json:"duration"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || len(reqForm.UIDS) == 0 || reqForm.Duration < 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+	for _, uid := range reqForm.UIDS {
+		if !dst.ValidUID(uid) {
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "invalid uid"), "data": uid})
+			return
+		}
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	username, _ := c.Get("username")
+	bans, err := scheduler.BanPlayers(reqForm.RoomID, reqForm.UIDS, reqForm.Reason, username.(string), reqForm.Duration)
+	if err != nil {
+		logger.Logger.Error("封禁玩家失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "ban fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "ban success"), "data": bans})
+}
+
+// banLiftPost 解封玩家
+func (h *Handler) banLiftPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int      `json:"roomID"`
+		UIDS   []string `json:"uids"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || len(reqForm.UIDS) == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	username, _ := c.Get("username")
+	if err := scheduler.LiftPlayerBans(reqForm.RoomID, reqForm.UIDS, username.(string)); err != nil {
+		logger.Logger.Error("解封玩家失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "lift ban fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "lift ban success"), "data": nil})
+}
+
+// actionPost 对游戏内玩家执行操作，封禁会同时记录到数据库
+func (h *Handler) actionPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int `json:"roomID"`
+		dst.PlayerAction
+		Reason   string `json:"reason"`
+		Duration int64  `json:"duration"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || reqForm.Duration < 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	if reqForm.Action == dst.PlayerActionBan {
+		if !dst.ValidUID(reqForm.UID) {
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "invalid uid"), "data": reqForm.UID})
+			return
+		}
+		username, _ := c.Get("username")
+		bans, err := scheduler.BanPlayers(reqForm.RoomID, []string{reqForm.UID}, reqForm.Reason, username.(string), reqForm.Duration)
+		if err != nil {
+			logger.Logger.Error("封禁玩家失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "ban fail"), "data": nil})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "ban success"), "data": bans})
+		return
+	}
+
+	if err := dst.ValidatePlayerAction(reqForm.PlayerAction); err != nil {
+		logger.Logger.Info("玩家操作参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "invalid player action"), "data": err.Error()})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	if err = game.PlayerAction(reqForm.PlayerAction); err != nil {
+		logger.Logger.Error("执行玩家操作失败", "err", err, "action", reqForm.Action)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "player action fail"), "data": nil})
+		return
+	}
+
+	username, _ := c.Get("username")
+	logger.Logger.Info("执行玩家操作", "action", reqForm.Action, "uid", reqForm.UID, "room", reqForm.RoomID, "user", username)
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "player action success"), "data": nil})
+}
+
+// whitelistInviteGet 获取房间的白名单邀请码
+func (h *Handler) whitelistInviteGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int `json:"roomID" form:"roomID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	invites, err := h.whitelistDao.GetInvitesByRoomID(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": invites})
+}
+
+// whitelistInvitePost 生成白名单邀请码，maxUses为0时不限次数，expiresIn单位小时，为0时永不过期
+func (h *Handler) whitelistInvitePost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID    int    `json:"roomID"`
+		MaxUses   int    `json:"maxUses"`
+		ExpiresIn int64  `json:"expiresIn"`
+		Note      string `json:"note"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || reqForm.MaxUses < 0 || reqForm.ExpiresIn < 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		logger.Logger.Error("生成邀请码失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "create invite fail"), "data": nil})
+		return
+	}
+
+	username, _ := c.Get("username")
+	now := utils.GetTimestamp()
+	invite := models.WhitelistInvite{
+		RoomID:    reqForm.RoomID,
+		Code:      code,
+		MaxUses:   reqForm.MaxUses,
+		Note:      reqForm.Note,
+		CreatedBy: username.(string),
+		CreatedAt: now,
+	}
+	if reqForm.ExpiresIn > 0 {
+		invite.ExpiresAt = now + reqForm.ExpiresIn*3600*1000
+	}
+	if err = h.whitelistDao.CreateInvite(&invite); err != nil {
+		logger.Logger.Error("创建邀请码失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "create invite success"), "data": invite})
+}
+
+// whitelistInviteDelete 删除白名单邀请码
+func (h *Handler) whitelistInviteDelete(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int `json:"roomID" form:"roomID"`
+		ID     int `json:"id" form:"id"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || reqForm.ID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	if err := h.whitelistDao.DeleteInvite(reqForm.RoomID, reqForm.ID); err != nil {
+		logger.Logger.Error("删除邀请码失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "delete success"), "data": nil})
+}
+
+// whitelistApplyPost 玩家申请白名单，不需要登录，使用有效邀请码时直接加入白名单，否则进入审核队列
+func (h *Handler) whitelistApplyPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID   int    `json:"roomID"`
+		UID      string `json:"uid"`
+		Nickname string `json:"nickname"`
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	now := utils.GetTimestamp()
+	if !allowWhitelistApply(c.ClientIP(), now) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "too many requests"), "data": nil})
+		return
+	}
+
+	reqForm.UID = strings.TrimSpace(reqForm.UID)
+	reqForm.Code = strings.ToUpper(strings.TrimSpace(reqForm.Code))
+	if !dst.ValidUID(reqForm.UID) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "invalid uid"), "data": nil})
+		return
+	}
+	if len([]rune(reqForm.Nickname)) > 64 || len([]rune(reqForm.Message)) > 500 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	var invite *models.WhitelistInvite
+	if reqForm.Code != "" {
+		var err error
+		invite, err = h.whitelistDao.GetInviteByCode(reqForm.Code)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		if invite.ID == 0 || (invite.ExpiresAt != 0 && invite.ExpiresAt < now) || (invite.MaxUses != 0 && invite.Uses >= invite.MaxUses) {
+			recordInviteFailure(c.ClientIP(), now)
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "invalid invite code"), "data": nil})
+			return
+		}
+		reqForm.RoomID = invite.RoomID
+	}
+
+	if reqForm.RoomID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	room, err := h.roomDao.GetRoomByID(reqForm.RoomID)
+	if err != nil || !room.Status {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "room not found"), "data": nil})
+		return
+	}
+
+	request, err := h.whitelistDao.GetPendingRequest(reqForm.RoomID, reqForm.UID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	// 没有邀请码的申请进入审核队列，重复申请只更新申请内容
+	if invite == nil {
+		if request.ID == 0 {
+			pending, err := h.whitelistDao.Count("room_id = ? AND status = ?", reqForm.RoomID, models.WhitelistRequestPending)
+			if err != nil {
+				logger.Logger.Error("查询数据库失败", "err", err)
+				c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+				return
+			}
+			if pending >= maxPendingWhitelistRequests {
+				c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "whitelist queue full"), "data": nil})
+				return
+			}
+		}
+		request.RoomID = reqForm.RoomID
+		request.UID = reqForm.UID
+		request.Nickname = reqForm.Nickname
+		request.Message = reqForm.Message
+		request.Status = models.WhitelistRequestPending
+		request.CreatedAt = now
+		if err = h.whitelistDao.UpdateRequest(request); err != nil {
+			logger.Logger.Error("更新数据库失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "whitelist apply submitted"), "data": nil})
+		return
+	}
+
+	ok, err := h.whitelistDao.UseInvite(invite.ID)
+	if err != nil {
+		logger.Logger.Error("更新数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "invalid invite code"), "data": nil})
+		return
+	}
+
+	if err = h.addToWhitelist(reqForm.RoomID, []string{reqForm.UID}, c.Request.Header.Get("X-I18n-Lang")); err != nil {
+		logger.Logger.Error("添加白名单失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "whitelist apply fail"), "data": nil})
+		return
+	}
+
+	request.RoomID = reqForm.RoomID
+	request.UID = reqForm.UID
+	request.Nickname = reqForm.Nickname
+	request.Message = reqForm.Message
+	request.Code = reqForm.Code
+	request.Status = models.WhitelistRequestApproved
+	request.CreatedAt = now
+	request.ReviewedBy = "invite"
+	request.ReviewedAt = now
+	if err = h.whitelistDao.UpdateRequest(request); err != nil {
+		logger.Logger.Error("更新数据库失败", "err", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "whitelist apply approved"), "data": nil})
+}
+
+// whitelistRequestGet 获取白名单申请，status为空时返回所有申请
+func (h *Handler) whitelistRequestGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int    `json:"roomID" form:"roomID"`
+		Status string `json:"status" form:"status"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	requests, err := h.whitelistDao.GetRequestsByRoomID(reqForm.RoomID, reqForm.Status)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": requests})
+}
+
+// whitelistReviewPost 审核白名单申请，通过的玩家会加入白名单
+func (h *Handler) whitelistReviewPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID  int   `json:"roomID"`
+		IDs     []int `json:"ids"`
+		Approve bool  `json:"approve"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || len(reqForm.IDs) == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	requests, err := h.whitelistDao.GetRequestsByIDs(reqForm.RoomID, reqForm.IDs)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	var pending []models.WhitelistRequest
+	var uids []string
+	for _, request := range *requests {
+		if request.Status != models.WhitelistRequestPending {
+			continue
+		}
+		pending = append(pending, request)
+		uids = append(uids, request.UID)
+	}
+
+	if reqForm.Approve && len(uids) != 0 {
+		if err = h.addToWhitelist(reqForm.RoomID, uids, c.Request.Header.Get("X-I18n-Lang")); err != nil {
+			logger.Logger.Error("添加白名单失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "review fail"), "data": nil})
+			return
+		}
+	}
+
+	username, _ := c.Get("username")
+	now := utils.GetTimestamp()
+	status := models.WhitelistRequestRejected
+	if reqForm.Approve {
+		status = models.WhitelistRequestApproved
+	}
+	for i := range pending {
+		pending[i].Status = status
+		pending[i].ReviewedBy = username.(string)
+		pending[i].ReviewedAt = now
+		if err = h.whitelistDao.UpdateRequest(&pending[i]); err != nil {
+			logger.Logger.Error("更新数据库失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "review success"), "data": pending})
+}
+
+// stateGet 获取玩家状态和物品
+func (h *Handler) stateGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int    `json:"roomID" form:"roomID"`
+		UID    string `json:"uid" form:"uid"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+	if !dst.ValidUID(reqForm.UID) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "invalid uid"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	state, err := game.PlayerState(reqForm.UID)
+	if err != nil {
+		logger.Logger.Info("获取玩家状态失败", "err", err, "uid", reqForm.UID)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "get player state fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": state})
+}
+
+// afkGet 获取玩家挂机检测状态
+func (h *Handler) afkGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int `json:"roomID" form:"roomID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	db.PlayersActivityMutex.Lock()
+	defer db.PlayersActivityMutex.Unlock()
+
+	activity := db.PlayersActivity[reqForm.RoomID]
+	if activity == nil {
+		activity = map[string]db.PlayerActivity{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": activity})
+}
+
+// leaderboardGet 玩家排行榜，metric为playtime、days、deaths或hours，roomID为0时统计所有房间
+// start和end为毫秒时间戳，不传时统计最近30天
+func (h *Handler) leaderboardGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int    `json:"roomID" form:"roomID"`
+		Metric string `json:"metric" form:"metric"`
+		Start  int64  `json:"start" form:"start"`
+		End    int64  `json:"end" form:"end"`
+		Limit  int    `json:"limit" form:"limit"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.End == 0 {
+		reqForm.End = utils.GetTimestamp()
+	}
+	if reqForm.Start == 0 {
+		reqForm.Start = reqForm.End - 30*24*3600*1000
+	}
+	if reqForm.Limit <= 0 || reqForm.Limit > 100 {
+		reqForm.Limit = 10
+	}
+	if reqForm.Start >= reqForm.End {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasDirectoryPermission(c, reqForm.RoomID) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	// 最活跃的时段，按本地时间每个小时的总在线时长降序
+	if reqForm.Metric == "hours" {
+		hourlyPlayTime, err := h.playerActivityDao.GetHourlyPlayTime(reqForm.RoomID, reqForm.Start, reqForm.End)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		type ActiveHour struct {
+			Hour    int   `json:"hour"`
+			Seconds int64 `json:"seconds"`
+		}
+		activeHours := make([]ActiveHour, 24)
+		for hour := range activeHours {
+			activeHours[hour].Hour = hour
+		}
+		for _, hourly := range hourlyPlayTime {
+			activeHours[time.UnixMilli(hourly.Hour).Hour()].Seconds += hourly.Seconds
+		}
+		sort.SliceStable(activeHours, func(i, j int) bool {
+			return activeHours[i].Seconds > activeHours[j].Seconds
+		})
+
+		c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": activeHours})
+		return
+	}
+
+	if reqForm.Metric != "playtime" && reqForm.Metric != "days" && reqForm.Metric != "deaths" {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	entries, err := h.playerActivityDao.GetLeaderboard(reqForm.Metric, reqForm.RoomID, reqForm.Start, reqForm.End, reqForm.Limit)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": entries})
+}
+
+// reportGet 获取活跃报告列表，roomID为0时为所有房间的报告
+func (h *Handler) reportGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int `json:"roomID" form:"roomID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasDirectoryPermission(c, reqForm.RoomID) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	reports, err := h.playerActivityDao.GetReports(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": reports})
+}
+
+// reportPost 立即生成指定时间范围的活跃报告
+func (h *Handler) reportPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int   `json:"roomID"`
+		Start  int64 `json:"start"`
+		End    int64 `json:"end"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.Start <= 0 || reqForm.Start >= reqForm.End {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasDirectoryPermission(c, reqForm.RoomID) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	username, _ := c.Get("username")
+	report, err := scheduler.GenerateActivityReport(reqForm.RoomID, reqForm.Start, reqForm.End, username.(string))
+	if err != nil {
+		logger.Logger.Error("生成活跃报告失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "generate report fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "generate report success"), "data": report})
+}
+
+// reportExportGet 导出活跃报告，format为json或csv
+func (h *Handler) reportExportGet(c *gin.Context) {
+	type ReqForm struct {
+		ID     int    `json:"id" form:"id"`
+		Format string `json:"format" form:"format"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.ID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	report, err := h.playerActivityDao.GetReportByID(reqForm.ID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if report.ID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "report not found"), "data": nil})
+		return
+	}
+
+	if !h.hasDirectoryPermission(c, report.RoomID) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	filename := fmt.Sprintf("activity_report_%d_%s", report.RoomID, time.UnixMilli(report.PeriodStart).Format("20060102"))
+
+	if reqForm.Format != "csv" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(report.Data))
+		return
+	}
+
+	var data scheduler.ActivityReportData
+	if err = json.Unmarshal([]byte(report.Data), &data); err != nil {
+		logger.Logger.Error("解析活跃报告失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "report not found"), "data": nil})
+		return
+	}
+	content, err := formatActivityReportCSV(data)
+	if err != nil {
+		logger.Logger.Error("生成csv失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "generate report fail"), "data": nil})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", content)
+}
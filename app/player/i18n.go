@@ -25,8 +25,62 @@ func NewExtendedI18n() *ExtendedI18n {
 	}
 
 	i.ZH["downloading"] = "开始下载模组"
+	i.ZH["player not found"] = "玩家目录中没有该玩家"
+	i.ZH["invalid uid"] = "UID格式错误"
+	i.ZH["ban fail"] = "封禁失败"
+	i.ZH["ban success"] = "封禁成功"
+	i.ZH["lift ban fail"] = "解封失败"
+	i.ZH["lift ban success"] = "解封成功，运行中的世界重启后完全生效"
+	i.ZH["invalid player action"] = "玩家操作参数错误"
+	i.ZH["player action fail"] = "执行失败，请检查世界是否运行"
+	i.ZH["player action success"] = "执行成功"
+	i.ZH["create invite fail"] = "生成邀请码失败"
+	i.ZH["create invite success"] = "生成邀请码成功"
+	i.ZH["invalid invite code"] = "邀请码无效或已用完"
+	i.ZH["room not found"] = "房间不存在"
+	i.ZH["whitelist queue full"] = "待审核的申请过多，请稍后再试"
+	i.ZH["too many requests"] = "请求过于频繁，请稍后再试"
+	i.ZH["whitelist apply fail"] = "申请失败"
+	i.ZH["whitelist apply submitted"] = "申请已提交，请等待管理员审核"
+	i.ZH["whitelist apply approved"] = "已加入白名单"
+	i.ZH["review fail"] = "审核失败"
+	i.ZH["review success"] = "审核成功"
+	i.ZH["get player state fail"] = "玩家不在线且未找到玩家存档"
+	i.ZH["generate report fail"] = "生成报告失败"
+	i.ZH["generate report success"] = "生成报告成功"
+	i.ZH["report not found"] = "报告不存在"
+	i.ZH["steam id not found"] = "服务器日志中没有该玩家的SteamID"
+	i.ZH["steam profile disabled"] = "未开启获取Steam玩家资料"
+	i.ZH["steam profile fail"] = "获取Steam资料失败"
 
 	i.EN["downloading"] = "开始下载模组"
+	i.EN["player not found"] = "Player Not Found In Directory"
+	i.EN["invalid uid"] = "Invalid UID"
+	i.EN["ban fail"] = "Ban Fail"
+	i.EN["ban success"] = "Ban Success"
+	i.EN["lift ban fail"] = "Lift Ban Fail"
+	i.EN["lift ban success"] = "Ban lifted, running worlds fully apply it after restart"
+	i.EN["invalid player action"] = "Invalid Player Action"
+	i.EN["player action fail"] = "Execute Fail, please check if the world is running"
+	i.EN["player action success"] = "Execute Success"
+	i.EN["create invite fail"] = "Create Invite Code Fail"
+	i.EN["create invite success"] = "Create Invite Code Success"
+	i.EN["invalid invite code"] = "Invite code is invalid or used up"
+	i.EN["room not found"] = "Room Not Found"
+	i.EN["whitelist queue full"] = "Too many pending requests, please try again later"
+	i.EN["too many requests"] = "Too many requests, please try again later"
+	i.EN["whitelist apply fail"] = "Apply Fail"
+	i.EN["whitelist apply submitted"] = "Request submitted, please wait for approval"
+	i.EN["whitelist apply approved"] = "Added To Whitelist"
+	i.EN["review fail"] = "Review Fail"
+	i.EN["review success"] = "Review Success"
+	i.EN["get player state fail"] = "Player is offline and no player save was found"
+	i.EN["generate report fail"] = "Generate Report Fail"
+	i.EN["generate report success"] = "Generate Report Success"
+	i.EN["report not found"] = "Report Not Found"
+	i.EN["steam id not found"] = "Steam ID of the player was not found in server logs"
+	i.EN["steam profile disabled"] = "Steam profile lookup is disabled"
+	i.EN["steam profile fail"] = "Get Steam Profile Fail"
 
 	return i
 }
@@ -10,6 +10,9 @@ import (
 func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	v := r.Group(utils.ApiVersion)
 	{
+		// 玩家申请白名单不需要登录
+		v.POST("/player/whitelist/apply", h.whitelistApplyPost)
+
 		player := v.Group("player")
 		player.Use(middleware.TokenCheck())
 		{
@@ -17,7 +20,26 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 			player.GET("/list", h.listGet)
 			player.POST("/list", h.listPost)
 			player.GET("/uidmap", h.uidMapGet)
+			player.GET("/directory", h.directoryGet)
+			player.GET("/directory/detail", h.directoryDetailGet)
+			player.PUT("/directory", middleware.AdminOnly(), h.directoryPut)
+			player.POST("/directory/steam", h.directorySteamPost)
+			player.GET("/ban", h.banGet)
+			player.POST("/ban", h.banPost)
+			player.POST("/ban/lift", h.banLiftPost)
+			player.POST("/action", h.actionPost)
+			player.GET("/state", h.stateGet)
+			player.GET("/whitelist/invite", h.whitelistInviteGet)
+			player.POST("/whitelist/invite", h.whitelistInvitePost)
+			player.DELETE("/whitelist/invite", h.whitelistInviteDelete)
+			player.GET("/whitelist/request", h.whitelistRequestGet)
+			player.POST("/whitelist/review", h.whitelistReviewPost)
 			player.GET("/statistics/online_time", h.statisticsOnlineTimeGet)
+			player.GET("/afk", h.afkGet)
+			player.GET("/leaderboard", h.leaderboardGet)
+			player.GET("/report", h.reportGet)
+			player.POST("/report", h.reportPost)
+			player.GET("/report/export", h.reportExportGet)
 			player.GET("/statistics/player_count", h.statisticsPlayerCountGet)
 		}
 	}
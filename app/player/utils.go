@@ -1,29 +1,50 @@
 package player
 
 import (
+	"bytes"
+	"crypto/rand"
 	"dst-management-platform-api/database/dao"
+	"dst-management-platform-api/database/db"
 	"dst-management-platform-api/database/models"
+	"dst-management-platform-api/dst"
 	"dst-management-platform-api/logger"
+	"dst-management-platform-api/scheduler"
+	"dst-management-platform-api/utils"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	userDao        *dao.UserDAO
-	roomDao        *dao.RoomDAO
-	worldDao       *dao.WorldDAO
-	roomSettingDao *dao.RoomSettingDAO
-	uidMapDao      *dao.UidMapDAO
+	userDao           *dao.UserDAO
+	roomDao           *dao.RoomDAO
+	worldDao          *dao.WorldDAO
+	roomSettingDao    *dao.RoomSettingDAO
+	globalSettingDao  *dao.GlobalSettingDAO
+	uidMapDao         *dao.UidMapDAO
+	playerDao         *dao.PlayerDAO
+	playerBanDao      *dao.PlayerBanDAO
+	whitelistDao      *dao.WhitelistDAO
+	playerActivityDao *dao.PlayerActivityDAO
 }
 
-func NewHandler(userDao *dao.UserDAO, roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO, uidMapDao *dao.UidMapDAO) *Handler {
+func NewHandler(userDao *dao.UserDAO, roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO, globalSettingDao *dao.GlobalSettingDAO, uidMapDao *dao.UidMapDAO, playerDao *dao.PlayerDAO, playerBanDao *dao.PlayerBanDAO, whitelistDao *dao.WhitelistDAO, playerActivityDao *dao.PlayerActivityDAO) *Handler {
 	return &Handler{
-		userDao:        userDao,
-		roomDao:        roomDao,
-		worldDao:       worldDao,
-		roomSettingDao: roomSettingDao,
-		uidMapDao:      uidMapDao,
+		userDao:           userDao,
+		roomDao:           roomDao,
+		worldDao:          worldDao,
+		roomSettingDao:    roomSettingDao,
+		globalSettingDao:  globalSettingDao,
+		uidMapDao:         uidMapDao,
+		playerDao:         playerDao,
+		playerBanDao:      playerBanDao,
+		whitelistDao:      whitelistDao,
+		playerActivityDao: playerActivityDao,
 	}
 }
 
@@ -67,3 +88,194 @@ func (h *Handler) hasPermission(c *gin.Context, roomID string) bool {
 
 	return false
 }
+
+// permittedRoomIDs 获取用户有权限的房间，管理员返回nil表示所有房间
+func (h *Handler) permittedRoomIDs(c *gin.Context) (map[int]bool, error) {
+	role, _ := c.Get("role")
+	username, _ := c.Get("username")
+	if role.(string) == "admin" {
+		return nil, nil
+	}
+
+	dbUser, err := h.userDao.GetUserByUsername(username.(string))
+	if err != nil {
+		return map[int]bool{}, err
+	}
+	roomIDs := make(map[int]bool)
+	for _, id := range strings.Split(dbUser.Rooms, ",") {
+		if roomID, err := strconv.Atoi(id); err == nil {
+			roomIDs[roomID] = true
+		}
+	}
+
+	return roomIDs, nil
+}
+
+// hasDirectoryPermission 跨房间查询玩家目录需要管理员权限，指定房间时需要有该房间的权限
+func (h *Handler) hasDirectoryPermission(c *gin.Context, roomID int) bool {
+	if roomID == 0 {
+		role, _ := c.Get("role")
+		return role.(string) == "admin"
+	}
+
+	return h.hasPermission(c, strconv.Itoa(roomID))
+}
+
+// maxPendingWhitelistRequests 每个房间待审核申请的上限，避免公开接口被刷
+const maxPendingWhitelistRequests = 200
+
+const (
+	// whitelistApplyWindow 白名单申请限流的时间窗口，单位毫秒
+	whitelistApplyWindow = 60 * 1000
+	// whitelistApplyMaxRequests 每个IP在时间窗口内最多的申请次数
+	whitelistApplyMaxRequests = 10
+	// whitelistApplyMaxInviteFailures 每个IP在锁定时长内最多输错邀请码的次数，超出后锁定
+	whitelistApplyMaxInviteFailures = 5
+	// whitelistApplyLockout 输错邀请码过多后的锁定时长，单位毫秒
+	whitelistApplyLockout = 15 * 60 * 1000
+	// whitelistApplyLimitPrune 记录的IP超过该数量时清理过期记录
+	whitelistApplyLimitPrune = 1000
+)
+
+// allowWhitelistApply 白名单申请按IP限流，被锁定或超出次数时返回false
+func allowWhitelistApply(ip string, now int64) bool {
+	db.WhitelistApplyLimitMutex.Lock()
+	defer db.WhitelistApplyLimitMutex.Unlock()
+
+	if len(db.WhitelistApplyLimit) > whitelistApplyLimitPrune {
+		for key, state := range db.WhitelistApplyLimit {
+			if now-state.WindowStart > whitelistApplyWindow && now-state.FailureStart > whitelistApplyLockout && state.LockedUntil < now {
+				delete(db.WhitelistApplyLimit, key)
+			}
+		}
+	}
+
+	state := db.WhitelistApplyLimit[ip]
+	if state.LockedUntil > now {
+		return false
+	}
+	if now-state.WindowStart > whitelistApplyWindow {
+		state.WindowStart = now
+		state.Requests = 0
+	}
+	state.Requests++
+	db.WhitelistApplyLimit[ip] = state
+
+	return state.Requests <= whitelistApplyMaxRequests
+}
+
+// recordInviteFailure 记录IP输错邀请码，次数过多时锁定该IP
+func recordInviteFailure(ip string, now int64) {
+	db.WhitelistApplyLimitMutex.Lock()
+	defer db.WhitelistApplyLimitMutex.Unlock()
+
+	state := db.WhitelistApplyLimit[ip]
+	if now-state.FailureStart > whitelistApplyLockout {
+		state.FailureStart = now
+		state.InviteFailures = 0
+	}
+	state.InviteFailures++
+	if state.InviteFailures >= whitelistApplyMaxInviteFailures {
+		state.LockedUntil = now + whitelistApplyLockout
+		state.InviteFailures = 0
+		logger.Logger.Warn("邀请码错误次数过多，锁定IP", "ip", ip)
+	}
+	db.WhitelistApplyLimit[ip] = state
+}
+
+// generateInviteCode 生成邀请码，去掉了容易混淆的字符
+func generateInviteCode() (string, error) {
+	const letters = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = letters[int(b[i])%len(letters)]
+	}
+
+	return string(b), nil
+}
+
+// addToWhitelist 将玩家添加到房间白名单，已在白名单中的玩家会被跳过，白名单变化后会重新生成cluster.ini
+func (h *Handler) addToWhitelist(roomID int, uids []string, lang string) error {
+	room, worlds, roomSetting, err := h.fetchGameInfo(roomID)
+	if err != nil {
+		return err
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, lang)
+	whitelist := game.GetPlayerList("whitelist")
+	var newUIDs []string
+	for _, uid := range utils.RemoveDuplicates(uids) {
+		if !slices.Contains(whitelist, uid) {
+			newUIDs = append(newUIDs, uid)
+		}
+	}
+	if len(newUIDs) == 0 {
+		return nil
+	}
+
+	return game.AddPlayerList(newUIDs, "whitelist")
+}
+
+// csvCell 玩家昵称等用户输入以=+-@开头时加上'，避免Excel打开时当作公式执行
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// formatActivityReportCSV 将活跃报告转换为csv，第一部分为汇总，第二部分为玩家明细
+func formatActivityReportCSV(data scheduler.ActivityReportData) ([]byte, error) {
+	var buf bytes.Buffer
+	// 写入BOM，避免Excel打开中文乱码
+	buf.WriteString("\xEF\xBB\xBF")
+	w := csv.NewWriter(&buf)
+
+	formatTime := func(ts int64) string {
+		if ts == 0 {
+			return ""
+		}
+		return time.UnixMilli(ts).Format("2006-01-02 15:04")
+	}
+
+	records := [][]string{
+		{"metric", "value"},
+		{"roomID", strconv.Itoa(data.RoomID)},
+		{"periodStart", formatTime(data.PeriodStart)},
+		{"periodEnd", formatTime(data.PeriodEnd)},
+		{"peakPlayers", strconv.Itoa(data.PeakPlayers)},
+		{"peakAt", formatTime(data.PeakAt)},
+		{"uniquePlayers", strconv.Itoa(data.UniquePlayers)},
+		{"newPlayers", strconv.Itoa(data.NewPlayers)},
+		{"previousNewPlayers", strconv.Itoa(data.PreviousNewPlayers)},
+		{"retainedPlayers", strconv.Itoa(data.RetainedPlayers)},
+		{"retention", strconv.FormatFloat(data.Retention, 'f', 4, 64)},
+		{"totalPlayTime", strconv.FormatInt(data.TotalPlayTime, 10)},
+		{"totalDeaths", strconv.Itoa(data.TotalDeaths)},
+	}
+	for hour, seconds := range data.ActiveHours {
+		records = append(records, []string{fmt.Sprintf("activeHour%02d", hour), strconv.FormatInt(seconds, 10)})
+	}
+	records = append(records, []string{}, []string{"uid", "nickname", "playTime", "deaths", "maxDays", "newPlayer"})
+	for _, player := range data.Players {
+		records = append(records, []string{
+			csvCell(player.UID),
+			csvCell(player.Nickname),
+			strconv.FormatInt(player.PlayTime, 10),
+			strconv.Itoa(player.Deaths),
+			strconv.Itoa(player.MaxDays),
+			strconv.FormatBool(slices.Contains(data.NewPlayerUIDs, player.UID)),
+		})
+	}
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -35,6 +35,15 @@ func (h *Handler) roomPost(c *gin.Context) {
 		}
 		//logger.Logger.Debug(utils.StructToFlatString(reqForm))
 
+		if reqForm.RoomSettingData.BackupRetentionSetting == "" {
+			reqForm.RoomSettingData.BackupRetentionSetting = dst.DefaultBackupRetentionSetting
+		}
+		if _, err := dst.ParseBackupRetention(reqForm.RoomSettingData.BackupCleanSetting, reqForm.RoomSettingData.BackupRetentionSetting); err != nil {
+			logger.Logger.Info("备份保留策略错误", "err", err, "api", c.Request.URL.Path)
+			c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+			return
+		}
+
 		reqForm.RoomData.ID = 0
 		reqForm.RoomData.Status = true
 
@@ -74,6 +83,7 @@ func (h *Handler) roomPost(c *gin.Context) {
 		}
 
 		processJobs(game, reqForm.RoomData.ID, reqForm.RoomSettingData)
+		scheduler.ApplyGlobalPlayerLists([]int{room.ID}, nil)
 
 		// 如果用户不是管理员，且拥有房间创建权限，需要在rooms字段中新增房间id
 		role, _ := c.Get("role")
@@ -108,6 +118,150 @@ func (h *Handler) roomPost(c *gin.Context) {
 	return
 }
 
+// roomClonePost 复制房间，端口和ClusterKey重新生成
+func (h *Handler) roomClonePost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID   int    `json:"roomID"`
+		GameName string `json:"gameName"`
+		dst.CloneOption
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	permission, err := h.hasCreatePermission(c)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if !permission || !h.hasRoomPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	room, err := h.roomDao.GetRoomByID(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	worlds, err := h.worldDao.GetWorldsByRoomID(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	roomSetting, err := h.roomSettingDao.GetRoomSettingsByRoomID(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+
+	// 新房间，激活状态与源房间一致
+	newRoom := *room
+	newRoom.ID = 0
+	newRoom.ClusterKey = utils.RandomString(14)
+	if reqForm.GameName != "" {
+		newRoom.GameName = reqForm.GameName
+	} else {
+		newRoom.GameName = room.GameName + " (copy)"
+	}
+	newWorlds := make([]models.World, len(*worlds))
+	copy(newWorlds, *worlds)
+	if !reqForm.Mod {
+		newRoom.ModData = ""
+		for index := range newWorlds {
+			newWorlds[index].ModData = ""
+		}
+	}
+	for index := range newWorlds {
+		newWorlds[index].ID = 0
+		newWorlds[index].LastAliveTime = ""
+	}
+
+	err = h.allocatePorts(&newRoom, newWorlds)
+	if err != nil {
+		logger.Logger.Error("分配端口失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	newRoomSetting := *roomSetting
+	if newRoomSetting.BackupRetentionSetting == "" {
+		newRoomSetting.BackupRetentionSetting = dst.DefaultBackupRetentionSetting
+	}
+
+	err = h.roomDao.CreateRoomWithWorlds(&newRoom, newWorlds, &newRoomSetting)
+	if err != nil {
+		logger.Logger.Error("创建房间失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	newGame := dst.NewGameController(&newRoom, &newWorlds, &newRoomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	err = game.CloneTo(newGame, reqForm.CloneOption)
+	if err != nil {
+		logger.Logger.Error("复制房间文件失败", "err", err)
+		// 复制失败时删除已创建的房间，避免留下不完整的房间
+		if errDelete := newGame.DeleteRoom(); errDelete != nil {
+			logger.Logger.Error("删除游戏相关文件失败", "err", errDelete)
+		}
+		if errDelete := h.roomDao.DeleteRoomWithWorlds(newRoom.ID); errDelete != nil {
+			logger.Logger.Error("删除房间失败", "err", errDelete)
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "clone fail"), "data": nil})
+		return
+	}
+
+	if newRoom.Status {
+		processJobs(newGame, newRoom.ID, newRoomSetting)
+	}
+	// 封禁记录属于源房间，复制名单时一并复制，否则重新生成blocklist.txt时会丢失
+	if reqForm.PlayerList {
+		if err = scheduler.CopyRoomBans(room.ID, newRoom.ID); err != nil {
+			logger.Logger.Error("复制封禁记录失败", "err", err)
+		}
+	}
+	scheduler.ApplyGlobalPlayerLists([]int{newRoom.ID}, nil)
+
+	// 如果用户不是管理员，需要在rooms字段中新增房间id
+	role, _ := c.Get("role")
+	username, _ := c.Get("username")
+	if role.(string) != "admin" {
+		user, err := h.userDao.GetUserByUsername(username.(string))
+		if err != nil {
+			logger.Logger.Error("获取用户信息失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+		var rooms []string
+		if user.Rooms != "" {
+			rooms = strings.Split(user.Rooms, ",")
+		}
+		rooms = append(rooms, strconv.Itoa(newRoom.ID))
+		user.Rooms = strings.Join(rooms, ",")
+		err = h.userDao.UpdateUser(user)
+		if err != nil {
+			logger.Logger.Error("更新用户信息失败", "err", err)
+			c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "clone success"), "data": newRoom})
+}
+
 // roomPut 修改房间
 func (h *Handler) roomPut(c *gin.Context) {
 	var reqForm XRoomTotalInfo
@@ -123,7 +277,24 @@ func (h *Handler) roomPut(c *gin.Context) {
 		return
 	}
 
-	err := h.roomDao.UpdateRoom(&reqForm.RoomData)
+	if _, err := dst.ParseBackupRetention(reqForm.RoomSettingData.BackupCleanSetting, reqForm.RoomSettingData.BackupRetentionSetting); err != nil {
+		logger.Logger.Info("备份保留策略错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+	if _, err := dst.ParseAfkSetting(reqForm.RoomSettingData.AfkSetting); err != nil {
+		logger.Logger.Info("挂机检测设置错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+	reservedSlotSetting, err := dst.ParseReservedSlotSetting(reqForm.RoomSettingData.ReservedSlotSetting)
+	if err != nil || reservedSlotSetting.Slots > reqForm.RoomData.MaxPlayer {
+		logger.Logger.Info("预留位设置错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	err = h.roomDao.UpdateRoom(&reqForm.RoomData)
 	if err != nil {
 		logger.Logger.Error("更新房间失败", "err", err)
 		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
@@ -522,6 +693,8 @@ func (h *Handler) uploadPost(c *gin.Context) {
 		roomSetting.BackupSetting = "[{\"time\":\"06:00:00\"}]"
 		roomSetting.BackupCleanEnable = false
 		roomSetting.BackupCleanSetting = 30
+		roomSetting.BackupRetentionSetting = dst.DefaultBackupRetentionSetting
+		roomSetting.BackupEventSetting = "{\"update\":true,\"reset\":true,\"restore\":true,\"mod\":false,\"dayMultiple\":0,\"seasonChange\":false}"
 		roomSetting.RestartEnable = false
 		roomSetting.RestartSetting = "06:30:00"
 		roomSetting.KeepaliveEnable = false
@@ -638,6 +811,12 @@ func (h *Handler) uploadPost(c *gin.Context) {
 	if err != nil {
 		logger.Logger.Error("设置预留位失败", "err", err)
 	}
+	// 黑名单由封禁记录生成，上传存档中的黑名单需要先导入为封禁
+	err = scheduler.ImportBlocklist(room.ID, strings.Fields(uploadExtraInfo.blocklist))
+	if err != nil {
+		logger.Logger.Error("导入黑名单失败", "err", err)
+	}
+	scheduler.ApplyGlobalPlayerLists([]int{room.ID}, nil)
 
 	// 覆盖save目录
 	for _, world := range uploadExtraInfo.worldPath {
@@ -876,6 +1055,12 @@ func (h *Handler) roomDelete(c *gin.Context) {
 	db.PlayersOnlineTimeMutex.Lock()
 	delete(db.PlayersOnlineTime, reqForm.RoomID)
 	db.PlayersOnlineTimeMutex.Unlock()
+	db.PlayersActivityMutex.Lock()
+	delete(db.PlayersActivity, reqForm.RoomID)
+	db.PlayersActivityMutex.Unlock()
+	db.PlayersJoinedAtMutex.Lock()
+	delete(db.PlayersJoinedAt, reqForm.RoomID)
+	db.PlayersJoinedAtMutex.Unlock()
 	// 更新用户权限
 	roomIDStr := strconv.Itoa(reqForm.RoomID)
 	for _, user := range *nonAdminUsers {
@@ -924,6 +1109,121 @@ func (h *Handler) roomDelete(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
 		return
 	}
+	err = h.backupPinDao.DeleteBackupPinsByRoomID(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("更新数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "delete success"), "data": nil})
 }
+
+// levelDataGet 获取世界配置
+func (h *Handler) levelDataGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID  int `json:"roomID" form:"roomID"`
+		WorldID int `json:"worldID" form:"worldID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasRoomPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	view, err := game.GetLevelDataView(reqForm.WorldID)
+	if err != nil {
+		logger.Logger.Error("解析世界配置失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "level data parse fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": view})
+}
+
+// levelDataPut 修改世界配置中的单个或多个配置项
+func (h *Handler) levelDataPut(c *gin.Context) {
+	type ReqForm struct {
+		RoomID    int               `json:"roomID"`
+		WorldID   int               `json:"worldID"`
+		Overrides map[string]string `json:"overrides"`
+		Force     bool              `json:"force"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if len(reqForm.Overrides) == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasRoomPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	errs, err := game.PatchLevelData(reqForm.WorldID, reqForm.Overrides, reqForm.Force)
+	if err != nil {
+		logger.Logger.Error("修改世界配置失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "level data parse fail"), "data": nil})
+		return
+	}
+	if len(errs) != 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "level data invalid"), "data": errs})
+		return
+	}
+
+	if err = h.worldDao.UpdateWorlds(worlds); err != nil {
+		logger.Logger.Error("写入数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "level data update success"), "data": nil})
+}
+
+// levelDataSchemaGet 获取世界配置项定义
+func (h *Handler) levelDataSchemaGet(c *gin.Context) {
+	type ReqForm struct {
+		Location string `json:"location" form:"location"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.Location != dst.LevelDataLocationForest && reqForm.Location != dst.LevelDataLocationCave {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": dst.GetLevelDataOptions(reqForm.Location, c.Request.Header.Get("X-I18n-Lang"))})
+}
@@ -46,6 +46,11 @@ func NewExtendedI18n() *ExtendedI18n {
 	i.ZH["deactivate success"] = "关闭成功"
 	i.ZH["activate fail"] = "激活成功"
 	i.ZH["activate success"] = "激活成功"
+	i.ZH["clone fail"] = "复制房间失败"
+	i.ZH["clone success"] = "复制房间成功"
+	i.ZH["level data parse fail"] = "世界配置解析失败"
+	i.ZH["level data invalid"] = "世界配置校验失败"
+	i.ZH["level data update success"] = "世界配置修改成功，世界生成选项需要重新生成世界后生效"
 
 	i.EN["room name exist"] = "Room Name Already Existed"
 	i.EN["upload save fail"] = "file save fail"
@@ -69,6 +74,11 @@ func NewExtendedI18n() *ExtendedI18n {
 	i.EN["deactivate success"] = "Deactivate Success"
 	i.EN["activate fail"] = "Activate Fail"
 	i.EN["activate success"] = "Activate Success"
+	i.EN["clone fail"] = "Clone Room Fail"
+	i.EN["clone success"] = "Clone Room Success"
+	i.EN["level data parse fail"] = "Parse World Settings Fail"
+	i.EN["level data invalid"] = "World Settings Validation Fail"
+	i.EN["level data update success"] = "World settings updated, world generation options take effect after regenerating the world"
 
 	return i
 }
@@ -21,8 +21,12 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 			room.GET("/basic", h.allRoomBasicGet)
 			room.GET("/worlds", h.roomWorldsGet)
 			room.POST("/upload", h.uploadPost)
+			room.POST("/clone", h.roomClonePost)
 			room.POST("/activate", h.activatePost)
 			room.POST("/deactivate", h.deactivatePost)
+			room.GET("/leveldata", h.levelDataGet)
+			room.PUT("/leveldata", h.levelDataPut)
+			room.GET("/leveldata/schema", h.levelDataSchemaGet)
 			room.DELETE("", middleware.AdminOnly(), h.roomDelete)
 		}
 	}
@@ -26,9 +26,10 @@ type Handler struct {
 	roomSettingDao   *dao.RoomSettingDAO
 	globalSettingDao *dao.GlobalSettingDAO
 	uidMapDao        *dao.UidMapDAO
+	backupPinDao     *dao.BackupPinDAO
 }
 
-func NewHandler(userDao *dao.UserDAO, roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO, globalSettingDao *dao.GlobalSettingDAO, uidMapDao *dao.UidMapDAO) *Handler {
+func NewHandler(userDao *dao.UserDAO, roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO, globalSettingDao *dao.GlobalSettingDAO, uidMapDao *dao.UidMapDAO, backupPinDao *dao.BackupPinDAO) *Handler {
 	return &Handler{
 		roomDao:          roomDao,
 		userDao:          userDao,
@@ -36,6 +37,7 @@ func NewHandler(userDao *dao.UserDAO, roomDao *dao.RoomDAO, worldDao *dao.WorldD
 		roomSettingDao:   roomSettingDao,
 		globalSettingDao: globalSettingDao,
 		uidMapDao:        uidMapDao,
+		backupPinDao:     backupPinDao,
 	}
 }
 
@@ -165,7 +167,7 @@ func processJobs(game *dst.Game, roomID int, roomSetting models.RoomSetting) {
 		err := scheduler.UpdateJob(&scheduler.JobConfig{
 			Name:     fmt.Sprintf("%d-BackupClean", roomID),
 			Func:     scheduler.BackupClean,
-			Args:     []any{roomID, roomSetting.BackupCleanSetting},
+			Args:     []any{roomID, roomSetting.BackupCleanSetting, roomSetting.BackupRetentionSetting},
 			TimeType: scheduler.DayType,
 			Interval: 0,
 			DayAt:    "05:16:27",
@@ -550,3 +552,55 @@ type UploadExtraInfo struct {
 	whitelist string
 	worldPath []WorldPath
 }
+
+// allocatePorts 为新房间分配未被占用的端口
+func (h *Handler) allocatePorts(room *models.Room, worlds []models.World) error {
+	masterPorts, err := h.roomDao.GetMasterPorts()
+	if err != nil {
+		return err
+	}
+	worldPorts, err := h.worldDao.GetPorts()
+	if err != nil {
+		return err
+	}
+
+	usedPorts := make(map[int]bool)
+	for _, port := range append(masterPorts, worldPorts...) {
+		usedPorts[port] = true
+	}
+
+	nextPort := func(base int) int {
+		port := base + 1
+		for usedPorts[port] {
+			port++
+		}
+		usedPorts[port] = true
+		return port
+	}
+
+	room.MasterPort = nextPort(21000)
+	for index := range worlds {
+		worlds[index].ServerPort = nextPort(11000)
+		worlds[index].MasterServerPort = nextPort(31000)
+		worlds[index].AuthenticationPort = nextPort(41000)
+	}
+
+	return nil
+}
+
+func (h *Handler) fetchGameInfo(roomID int) (*models.Room, *[]models.World, *models.RoomSetting, error) {
+	room, err := h.roomDao.GetRoomByID(roomID)
+	if err != nil {
+		return &models.Room{}, &[]models.World{}, &models.RoomSetting{}, err
+	}
+	worlds, err := h.worldDao.GetWorldsByRoomID(roomID)
+	if err != nil {
+		return &models.Room{}, &[]models.World{}, &models.RoomSetting{}, err
+	}
+	roomSetting, err := h.roomSettingDao.GetRoomSettingsByRoomID(roomID)
+	if err != nil {
+		return &models.Room{}, &[]models.World{}, &models.RoomSetting{}, err
+	}
+
+	return room, worlds, roomSetting, nil
+}
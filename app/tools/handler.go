@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -52,6 +53,16 @@ func (h *Handler) backupGet(c *gin.Context) {
 		return
 	}
 
+	pinned, err := h.backupPinDao.GetPinnedFileNames(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	for i := range backups {
+		backups[i].Pinned = utils.Contains(pinned, backups[i].FileName)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": backups})
 }
 
@@ -123,8 +134,22 @@ func (h *Handler) backupDelete(c *gin.Context) {
 		return
 	}
 
+	// 锁定的备份不允许删除
+	pinned, err := h.backupPinDao.GetPinnedFileNames(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	var filenames []string
+	for _, filename := range reqForm.Filenames {
+		if !utils.Contains(pinned, filename) {
+			filenames = append(filenames, filename)
+		}
+	}
+
 	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
-	count := game.DeleteBackups(reqForm.Filenames)
+	count := game.DeleteBackups(filenames)
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "?", "data": count})
 }
@@ -219,6 +244,292 @@ func (h *Handler) backupDownloadGet(c *gin.Context) {
 	c.File(filePath)
 }
 
+func (h *Handler) backupPinPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID   int    `json:"roomID"`
+		Filename string `json:"filename"`
+		Note     string `json:"note"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || reqForm.Filename == "" {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	// 只能锁定房间备份目录中已有的备份
+	if filepath.Base(reqForm.Filename) != reqForm.Filename || strings.Contains(reqForm.Filename, "..") {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+	if !utils.FileDirectoryExists(fmt.Sprintf("dmp_files/backup/%d/%s", reqForm.RoomID, reqForm.Filename)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "backup not found"), "data": nil})
+		return
+	}
+
+	pinned, err := h.backupPinDao.GetPinnedFileNames(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+	if utils.Contains(pinned, reqForm.Filename) {
+		c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "pin success"), "data": nil})
+		return
+	}
+
+	err = h.backupPinDao.Create(&models.BackupPin{
+		RoomID:   reqForm.RoomID,
+		FileName: reqForm.Filename,
+		Note:     reqForm.Note,
+	})
+	if err != nil {
+		logger.Logger.Error("锁定备份失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "pin success"), "data": nil})
+}
+
+func (h *Handler) backupPinDelete(c *gin.Context) {
+	type ReqForm struct {
+		RoomID   int    `json:"roomID"`
+		Filename string `json:"filename"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || reqForm.Filename == "" {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	err := h.backupPinDao.DeleteBackupPin(reqForm.RoomID, reqForm.Filename)
+	if err != nil {
+		logger.Logger.Error("解除备份锁定失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "unpin success"), "data": nil})
+}
+
+func (h *Handler) backupCleanPreviewGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int `json:"roomID" form:"roomID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	// 未开启备份清理时，下次清理不会删除任何文件
+	if !roomSetting.BackupCleanEnable {
+		c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": []dst.BackupCleanItem{}})
+		return
+	}
+
+	retention, err := dst.ParseBackupRetention(roomSetting.BackupCleanSetting, roomSetting.BackupRetentionSetting)
+	if err != nil {
+		logger.Logger.Error("解析备份保留策略失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "get setting fail"), "data": nil})
+		return
+	}
+
+	pinned, err := h.backupPinDao.GetPinnedFileNames(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	items, err := game.PreviewBackupClean(retention, pinned)
+	if err != nil {
+		logger.Logger.Error("获取备份文件失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "get backup fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": items})
+}
+
+func (h *Handler) backupTreeGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID   int    `json:"roomID" form:"roomID"`
+		Filename string `json:"filename" form:"filename"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || reqForm.Filename == "" {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	tree, err := game.GetBackupTree(reqForm.Filename)
+	if err != nil {
+		logger.Logger.Error("获取备份文件目录失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "get backup fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": tree})
+}
+
+func (h *Handler) backupFileGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID   int    `json:"roomID" form:"roomID"`
+		Filename string `json:"filename" form:"filename"`
+		Path     string `json:"path" form:"path"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || reqForm.Filename == "" || reqForm.Path == "" {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	content, err := game.ReadBackupFile(reqForm.Filename, reqForm.Path)
+	if err != nil {
+		logger.Logger.Error("读取备份文件失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "read backup file fail"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": content})
+}
+
+func (h *Handler) backupExtractPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID   int    `json:"roomID"`
+		Filename string `json:"filename"`
+		Path     string `json:"path"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || reqForm.Filename == "" || reqForm.Path == "" {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	err = game.ExtractBackupFile(reqForm.Filename, reqForm.Path)
+	if err != nil {
+		logger.Logger.Error("解压备份文件失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "extract fail"), "data": nil})
+		return
+	}
+
+	// 世界配置文件会同步到房间信息中
+	err = h.roomDao.UpdateRoom(room)
+	if err != nil {
+		logger.Logger.Error("更新房间失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	err = h.worldDao.UpdateWorlds(worlds)
+	if err != nil {
+		logger.Logger.Error("更新世界失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "extract success"), "data": nil})
+}
+
 func (h *Handler) announceGet(c *gin.Context) {
 	type ReqForm struct {
 		RoomID int `json:"roomID" form:"roomID"`
@@ -550,3 +861,110 @@ func (h *Handler) snapshotDelete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "delete success"), "data": nil})
 }
+
+func (h *Handler) snapshotRollbackPost(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int    `json:"roomID"`
+		Name   string `json:"name"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindJSON(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if reqForm.RoomID == 0 || reqForm.Name == "" {
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	room, worlds, roomSetting, err := h.fetchGameInfo(reqForm.RoomID)
+	if err != nil {
+		logger.Logger.Error("获取基本信息失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 500, "message": message.Get(c, "database error"), "data": nil})
+		return
+	}
+
+	// 检查和占用在同一把锁内完成，避免同时发起两次回档
+	db.RollbackProgressMutex.Lock()
+	if status, ok := db.RollbackProgress[reqForm.RoomID]; ok && !status.Done {
+		db.RollbackProgressMutex.Unlock()
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "rollback running"), "data": status})
+		return
+	}
+	db.RollbackProgress[reqForm.RoomID] = db.RollbackStatus{
+		Name:          reqForm.Name,
+		CurrentCycles: -1,
+		StartedAt:     utils.GetTimestamp(),
+	}
+	db.RollbackProgressMutex.Unlock()
+
+	game := dst.NewGameController(room, worlds, roomSetting, c.Request.Header.Get("X-I18n-Lang"))
+	result, err := game.StartRollback(reqForm.Name, func(result *dst.RollbackResult, err error) {
+		db.RollbackProgressMutex.Lock()
+		defer db.RollbackProgressMutex.Unlock()
+		status := db.RollbackProgress[reqForm.RoomID]
+		status.CurrentCycles = result.CurrentCycles
+		status.Verified = result.Verified
+		status.Done = true
+		status.FinishedAt = utils.GetTimestamp()
+		if err != nil {
+			status.Error = err.Error()
+			logger.Logger.Error("回档失败", "err", err, "room", reqForm.RoomID)
+		} else if !result.Verified {
+			logger.Logger.Warn("回档后天数校验未通过", "expected", result.ExpectedCycles, "current", result.CurrentCycles)
+		}
+		db.RollbackProgress[reqForm.RoomID] = status
+	})
+	if err != nil {
+		db.RollbackProgressMutex.Lock()
+		delete(db.RollbackProgress, reqForm.RoomID)
+		db.RollbackProgressMutex.Unlock()
+		logger.Logger.Error("回档失败", "err", err)
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "rollback fail"), "data": nil})
+		return
+	}
+
+	db.RollbackProgressMutex.Lock()
+	status := db.RollbackProgress[reqForm.RoomID]
+	status.Count = result.Count
+	status.ExpectedCycles = result.ExpectedCycles
+	db.RollbackProgress[reqForm.RoomID] = status
+	db.RollbackProgressMutex.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message.Get(c, "rollback started"), "data": status})
+}
+
+// snapshotRollbackStatusGet 获取回档进度，Done为true且Verified为false时表示未能确认天数
+func (h *Handler) snapshotRollbackStatusGet(c *gin.Context) {
+	type ReqForm struct {
+		RoomID int `form:"roomID"`
+	}
+	var reqForm ReqForm
+	if err := c.ShouldBindQuery(&reqForm); err != nil {
+		logger.Logger.Info("请求参数错误", "err", err, "api", c.Request.URL.Path)
+		c.JSON(http.StatusOK, gin.H{"code": 400, "message": message.Get(c, "bad request"), "data": nil})
+		return
+	}
+
+	if !h.hasPermission(c, strconv.Itoa(reqForm.RoomID)) {
+		c.JSON(http.StatusOK, gin.H{"code": 201, "message": message.Get(c, "permission needed"), "data": nil})
+		return
+	}
+
+	db.RollbackProgressMutex.Lock()
+	status, ok := db.RollbackProgress[reqForm.RoomID]
+	db.RollbackProgressMutex.Unlock()
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": status})
+}
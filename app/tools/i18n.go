@@ -32,6 +32,15 @@ func NewExtendedI18n() *ExtendedI18n {
 	i.ZH["get setting fail"] = "获取定时通知设置失败"
 	i.ZH["generate map fail"] = "生成地图失败"
 	i.ZH["get snapshot fail"] = "获取备份文件失败"
+	i.ZH["rollback fail"] = "回档失败"
+	i.ZH["rollback started"] = "开始回档，请稍后查看回档结果"
+	i.ZH["rollback running"] = "正在回档中"
+	i.ZH["read backup file fail"] = "读取备份文件失败"
+	i.ZH["extract fail"] = "解压失败"
+	i.ZH["extract success"] = "解压成功"
+	i.ZH["pin success"] = "锁定成功"
+	i.ZH["backup not found"] = "备份文件不存在"
+	i.ZH["unpin success"] = "解除锁定成功"
 
 	i.EN["get backup fail"] = "get backup fail"
 	i.EN["create backup fail"] = "create backup fail"
@@ -41,6 +50,15 @@ func NewExtendedI18n() *ExtendedI18n {
 	i.EN["get setting fail"] = "Get Announce Settings Fail"
 	i.EN["generate map fail"] = "generate map fail"
 	i.EN["get snapshot fail"] = "get snapshot fail"
+	i.EN["rollback fail"] = "rollback fail"
+	i.EN["rollback started"] = "rollback started, please check the result later"
+	i.EN["rollback running"] = "rollback is running"
+	i.EN["read backup file fail"] = "read backup file fail"
+	i.EN["extract fail"] = "extract fail"
+	i.EN["extract success"] = "extract success"
+	i.EN["pin success"] = "pin success"
+	i.EN["backup not found"] = "backup file not found"
+	i.EN["unpin success"] = "unpin success"
 
 	return i
 }
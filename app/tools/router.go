@@ -18,12 +18,20 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 			tools.DELETE("/backup", h.backupDelete)
 			tools.POST("/backup/restore", h.backupRestorePost)
 			tools.GET("/backup/download", h.backupDownloadGet)
+			tools.GET("/backup/tree", h.backupTreeGet)
+			tools.GET("/backup/file", h.backupFileGet)
+			tools.POST("/backup/extract", h.backupExtractPost)
+			tools.POST("/backup/pin", h.backupPinPost)
+			tools.DELETE("/backup/pin", h.backupPinDelete)
+			tools.GET("/backup/clean/preview", h.backupCleanPreviewGet)
 			tools.GET("/announce", h.announceGet)
 			tools.PUT("/announce", h.announcePut)
 			tools.GET("/map", h.mapGet)
 			tools.POST("/token", middleware.AdminOnly(), tokenPost)
 			tools.GET("/snapshot", h.snapshotGet)
 			tools.DELETE("/snapshot", h.snapshotDelete)
+			tools.POST("/snapshot/rollback", h.snapshotRollbackPost)
+			tools.GET("/snapshot/rollback", h.snapshotRollbackStatusGet)
 		}
 	}
 }
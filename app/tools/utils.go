@@ -14,14 +14,16 @@ type Handler struct {
 	userDao        *dao.UserDAO
 	worldDao       *dao.WorldDAO
 	roomSettingDao *dao.RoomSettingDAO
+	backupPinDao   *dao.BackupPinDAO
 }
 
-func NewHandler(userDao *dao.UserDAO, roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO) *Handler {
+func NewHandler(userDao *dao.UserDAO, roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO, backupPinDao *dao.BackupPinDAO) *Handler {
 	return &Handler{
 		roomDao:        roomDao,
 		userDao:        userDao,
 		worldDao:       worldDao,
 		roomSettingDao: roomSettingDao,
+		backupPinDao:   backupPinDao,
 	}
 }
 
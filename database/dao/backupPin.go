@@ -0,0 +1,40 @@
+package dao
+
+import (
+	"dst-management-platform-api/database/models"
+
+	"gorm.io/gorm"
+)
+
+type BackupPinDAO struct {
+	BaseDAO[models.BackupPin]
+}
+
+func NewBackupPinDAO(db *gorm.DB) *BackupPinDAO {
+	return &BackupPinDAO{
+		BaseDAO: *NewBaseDAO[models.BackupPin](db),
+	}
+}
+
+func (d *BackupPinDAO) GetBackupPinsByRoomID(roomID int) (*[]models.BackupPin, error) {
+	var backupPins []models.BackupPin
+	err := d.db.Where("room_id = ?", roomID).Find(&backupPins).Error
+
+	return &backupPins, err
+}
+
+// GetPinnedFileNames 获取房间所有锁定的备份文件名
+func (d *BackupPinDAO) GetPinnedFileNames(roomID int) ([]string, error) {
+	var fileNames []string
+	err := d.db.Model(&models.BackupPin{}).Where("room_id = ?", roomID).Pluck("file_name", &fileNames).Error
+
+	return fileNames, err
+}
+
+func (d *BackupPinDAO) DeleteBackupPin(roomID int, fileName string) error {
+	return d.db.Where("room_id = ? AND file_name = ?", roomID, fileName).Delete(&models.BackupPin{}).Error
+}
+
+func (d *BackupPinDAO) DeleteBackupPinsByRoomID(roomID int) error {
+	return d.db.Where("room_id = ?", roomID).Delete(&models.BackupPin{}).Error
+}
@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"dst-management-platform-api/database/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type GlobalPlayerListDAO struct {
+	BaseDAO[models.GlobalPlayerList]
+}
+
+func NewGlobalPlayerListDAO(db *gorm.DB) *GlobalPlayerListDAO {
+	return &GlobalPlayerListDAO{
+		BaseDAO: *NewBaseDAO[models.GlobalPlayerList](db),
+	}
+}
+
+// GetGlobalPlayerLists 获取平台级名单，listType为空时返回所有名单
+func (d *GlobalPlayerListDAO) GetGlobalPlayerLists(listType string) (*[]models.GlobalPlayerList, error) {
+	var lists []models.GlobalPlayerList
+	query := d.db.Order("id")
+	if listType != "" {
+		query = query.Where("list_type = ?", listType)
+	}
+	err := query.Find(&lists).Error
+
+	return &lists, err
+}
+
+func (d *GlobalPlayerListDAO) GetGlobalPlayerListByID(id int) (*models.GlobalPlayerList, error) {
+	var list models.GlobalPlayerList
+	err := d.db.Where("id = ?", id).First(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &list, nil
+	}
+	return &list, err
+}
+
+func (d *GlobalPlayerListDAO) GetGlobalPlayerListByUID(listType, uid string) (*models.GlobalPlayerList, error) {
+	var list models.GlobalPlayerList
+	err := d.db.Where("list_type = ? AND uid = ?", listType, uid).First(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &list, nil
+	}
+	return &list, err
+}
+
+func (d *GlobalPlayerListDAO) UpdateGlobalPlayerList(list *models.GlobalPlayerList) error {
+	return d.db.Save(list).Error
+}
@@ -0,0 +1,48 @@
+package dao
+
+import (
+	"dst-management-platform-api/database/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type LocalModDAO struct {
+	BaseDAO[models.LocalMod]
+}
+
+func NewLocalModDAO(db *gorm.DB) *LocalModDAO {
+	return &LocalModDAO{
+		BaseDAO: *NewBaseDAO[models.LocalMod](db),
+	}
+}
+
+func (d *LocalModDAO) GetLocalMods() (*[]models.LocalMod, error) {
+	var localMods []models.LocalMod
+	err := d.db.Order("name").Order("id desc").Find(&localMods).Error
+
+	return &localMods, err
+}
+
+func (d *LocalModDAO) GetLocalModVersion(name, version string) (*models.LocalMod, error) {
+	var localMod models.LocalMod
+	err := d.db.Where("name = ? AND version = ?", name, version).First(&localMod).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &localMod, nil
+	}
+	return &localMod, err
+}
+
+// SetCurrentVersion 将指定版本标记为当前安装的版本
+func (d *LocalModDAO) SetCurrentVersion(name string, id int) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.LocalMod{}).Where("name = ?", name).Update("current", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.LocalMod{}).Where("id = ?", id).Update("current", true).Error
+	})
+}
+
+func (d *LocalModDAO) DeleteLocalModsByName(name string) error {
+	return d.db.Where("name = ?", name).Delete(&models.LocalMod{}).Error
+}
@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"dst-management-platform-api/database/models"
+
+	"gorm.io/gorm"
+)
+
+type ModDownloadJobDAO struct {
+	BaseDAO[models.ModDownloadJob]
+}
+
+func NewModDownloadJobDAO(db *gorm.DB) *ModDownloadJobDAO {
+	return &ModDownloadJobDAO{
+		BaseDAO: *NewBaseDAO[models.ModDownloadJob](db),
+	}
+}
+
+func (d *ModDownloadJobDAO) GetJobByID(id int) (*models.ModDownloadJob, error) {
+	var job models.ModDownloadJob
+	err := d.db.Where("id = ?", id).First(&job).Error
+
+	return &job, err
+}
+
+func (d *ModDownloadJobDAO) GetJobsByRoomID(roomID int) (*[]models.ModDownloadJob, error) {
+	var jobs []models.ModDownloadJob
+	err := d.db.Where("room_id = ?", roomID).Order("id desc").Find(&jobs).Error
+
+	return &jobs, err
+}
+
+// GetNextQueuedJob 获取最早加入队列且已到重试时间的任务
+func (d *ModDownloadJobDAO) GetNextQueuedJob(now int64) (*models.ModDownloadJob, error) {
+	var jobs []models.ModDownloadJob
+	err := d.db.Where("status = ? AND next_run_at <= ?", models.ModDownloadQueued, now).Order("id").Limit(1).Find(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+
+	return &jobs[0], nil
+}
+
+// UpdateStatusIf 仅当任务处于from状态时修改为to，返回是否修改成功，用于避免取消和开始下载同时发生
+func (d *ModDownloadJobDAO) UpdateStatusIf(id int, from, to string, now int64) (bool, error) {
+	result := d.db.Model(&models.ModDownloadJob{}).Where("id = ? AND status = ?", id, from).Updates(map[string]any{"status": to, "updated_at": now})
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// ResetRunningJobs 程序重启后，将中断的任务重新加入队列
+func (d *ModDownloadJobDAO) ResetRunningJobs() error {
+	return d.db.Model(&models.ModDownloadJob{}).Where("status = ?", models.ModDownloadRunning).Update("status", models.ModDownloadQueued).Error
+}
+
+// DeleteFinishedJobs 删除房间已结束的任务
+func (d *ModDownloadJobDAO) DeleteFinishedJobs(roomID int) error {
+	return d.db.Where("room_id = ? AND status IN ?", roomID, []string{models.ModDownloadSucceeded, models.ModDownloadFailed, models.ModDownloadCanceled}).Delete(&models.ModDownloadJob{}).Error
+}
@@ -0,0 +1,31 @@
+package dao
+
+import (
+	"dst-management-platform-api/database/models"
+
+	"gorm.io/gorm"
+)
+
+type ModProfileDAO struct {
+	BaseDAO[models.ModProfile]
+}
+
+func NewModProfileDAO(db *gorm.DB) *ModProfileDAO {
+	return &ModProfileDAO{
+		BaseDAO: *NewBaseDAO[models.ModProfile](db),
+	}
+}
+
+func (d *ModProfileDAO) GetModProfiles() (*[]models.ModProfile, error) {
+	var profiles []models.ModProfile
+	err := d.db.Order("id").Find(&profiles).Error
+
+	return &profiles, err
+}
+
+func (d *ModProfileDAO) GetModProfileByID(id int) (*models.ModProfile, error) {
+	var profile models.ModProfile
+	err := d.db.Where("id = ?", id).First(&profile).Error
+
+	return &profile, err
+}
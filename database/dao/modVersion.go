@@ -0,0 +1,45 @@
+package dao
+
+import (
+	"dst-management-platform-api/database/models"
+
+	"gorm.io/gorm"
+)
+
+type ModVersionDAO struct {
+	BaseDAO[models.ModVersion]
+}
+
+func NewModVersionDAO(db *gorm.DB) *ModVersionDAO {
+	return &ModVersionDAO{
+		BaseDAO: *NewBaseDAO[models.ModVersion](db),
+	}
+}
+
+func (d *ModVersionDAO) GetModVersionsByRoomID(roomID int) (*[]models.ModVersion, error) {
+	var modVersions []models.ModVersion
+	err := d.db.Where("room_id = ?", roomID).Find(&modVersions).Error
+
+	return &modVersions, err
+}
+
+func (d *ModVersionDAO) GetOutdatedModVersions(roomID int) (*[]models.ModVersion, error) {
+	var modVersions []models.ModVersion
+	err := d.db.Where("room_id = ? AND outdated = ?", roomID, true).Find(&modVersions).Error
+
+	return &modVersions, err
+}
+
+// UpdateModVersions 覆盖房间的所有模组版本记录
+func (d *ModVersionDAO) UpdateModVersions(roomID int, modVersions *[]models.ModVersion) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("room_id = ?", roomID).Delete(&models.ModVersion{}).Error; err != nil {
+			return err
+		}
+		if modVersions == nil || len(*modVersions) == 0 {
+			return nil
+		}
+
+		return tx.Create(modVersions).Error
+	})
+}
@@ -0,0 +1,170 @@
+package dao
+
+import (
+	"dst-management-platform-api/database/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PlayerDAO struct {
+	BaseDAO[models.Player]
+}
+
+func NewPlayerDAO(db *gorm.DB) *PlayerDAO {
+	return &PlayerDAO{
+		BaseDAO: *NewBaseDAO[models.Player](db),
+	}
+}
+
+// RecordPresence 记录玩家在房间中出现一次，playTime为本次增加的在线时长
+func (d *PlayerDAO) RecordPresence(uid, nickname string, roomID int, now, playTime int64) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "uid"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"nickname":  nickname,
+				"last_seen": now,
+				"play_time": gorm.Expr("play_time + ?", playTime),
+			}),
+		}).Create(&models.Player{
+			UID:       uid,
+			Nickname:  nickname,
+			FirstSeen: now,
+			LastSeen:  now,
+			PlayTime:  playTime,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "uid"}, {Name: "nickname"}},
+			DoUpdates: clause.Assignments(map[string]any{"last_seen": now}),
+		}).Create(&models.PlayerNickname{
+			UID:       uid,
+			Nickname:  nickname,
+			FirstSeen: now,
+			LastSeen:  now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "uid"}, {Name: "room_id"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"nickname":  nickname,
+				"last_seen": now,
+				"play_time": gorm.Expr("play_time + ?", playTime),
+			}),
+		}).Create(&models.PlayerPresence{
+			UID:       uid,
+			RoomID:    roomID,
+			Nickname:  nickname,
+			FirstSeen: now,
+			LastSeen:  now,
+			PlayTime:  playTime,
+		}).Error
+	})
+}
+
+// SearchPlayers 按UID或历史昵称模糊搜索，roomID不为0时只返回在该房间出现过的玩家
+func (d *PlayerDAO) SearchPlayers(q, tag string, roomID, page, pageSize int) (*PaginatedResult[models.Player], error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if q != "" {
+		search := "%" + q + "%"
+		conditions = append(conditions, "(uid LIKE ? OR nickname LIKE ? OR uid IN (SELECT uid FROM player_nicknames WHERE nickname LIKE ?))")
+		args = append(args, search, search, search)
+	}
+	if tag != "" {
+		conditions = append(conditions, "(',' || tags || ',') LIKE ?")
+		args = append(args, "%,"+tag+",%")
+	}
+	if roomID != 0 {
+		conditions = append(conditions, "uid IN (SELECT uid FROM player_presences WHERE room_id = ?)")
+		args = append(args, roomID)
+	}
+
+	if len(conditions) == 0 {
+		return d.Query(page, pageSize, nil)
+	}
+
+	condition := conditions[0]
+	for _, c := range conditions[1:] {
+		condition += " AND " + c
+	}
+
+	return d.Query(page, pageSize, condition, args...)
+}
+
+func (d *PlayerDAO) GetPlayerByUID(uid string) (*models.Player, error) {
+	var player models.Player
+	err := d.db.Where("uid = ?", uid).First(&player).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &player, nil
+	}
+	return &player, err
+}
+
+func (d *PlayerDAO) GetNicknamesByUID(uid string) (*[]models.PlayerNickname, error) {
+	var nicknames []models.PlayerNickname
+	err := d.db.Where("uid = ?", uid).Order("last_seen desc").Find(&nicknames).Error
+
+	return &nicknames, err
+}
+
+func (d *PlayerDAO) GetPresencesByUID(uid string) (*[]models.PlayerPresence, error) {
+	var presences []models.PlayerPresence
+	err := d.db.Where("uid = ?", uid).Order("last_seen desc").Find(&presences).Error
+
+	return &presences, err
+}
+
+// UpdatePlayerNotes 修改管理员备注和标签
+func (d *PlayerDAO) UpdatePlayerNotes(uid, notes, tags string) error {
+	return d.db.Model(&models.Player{}).Where("uid = ?", uid).Updates(map[string]any{"notes": notes, "tags": tags}).Error
+}
+
+// UpdateSteamID 记录玩家的SteamID，SteamID变化时清空更新时间以便重新获取Steam资料
+func (d *PlayerDAO) UpdateSteamID(uid, steamID string) error {
+	return d.db.Model(&models.Player{}).Where("uid = ? AND steam_id <> ?", uid, steamID).Updates(map[string]any{"steam_id": steamID, "steam_updated_at": 0}).Error
+}
+
+// GetPlayersForSteamProfile 获取有SteamID且Steam资料在before之前更新的玩家，最久未更新的优先
+func (d *PlayerDAO) GetPlayersForSteamProfile(before int64, limit int) (*[]models.Player, error) {
+	var players []models.Player
+	err := d.db.Where("steam_id <> '' AND steam_updated_at < ?", before).Order("steam_updated_at asc").Limit(limit).Find(&players).Error
+
+	return &players, err
+}
+
+// UpdateSteamProfile 更新玩家的Steam资料
+func (d *PlayerDAO) UpdateSteamProfile(player *models.Player) error {
+	return d.db.Model(&models.Player{}).Where("uid = ?", player.UID).Updates(map[string]any{
+		"steam_name":       player.SteamName,
+		"steam_avatar":     player.SteamAvatar,
+		"steam_created_at": player.SteamCreatedAt,
+		"steam_updated_at": player.SteamUpdatedAt,
+	}).Error
+}
+
+// ImportUidMap 将旧的uid_map中还没有进入玩家目录的玩家导入
+func (d *PlayerDAO) ImportUidMap(now int64) (int64, error) {
+	var uidMaps []models.UidMap
+	if err := d.db.Where("uid NOT IN (SELECT uid FROM players)").Find(&uidMaps).Error; err != nil {
+		return 0, err
+	}
+
+	for _, uidMap := range uidMaps {
+		if err := d.RecordPresence(uidMap.UID, uidMap.Nickname, uidMap.RoomID, now, 0); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(uidMaps)), nil
+}
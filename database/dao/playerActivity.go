@@ -0,0 +1,209 @@
+package dao
+
+import (
+	"dst-management-platform-api/database/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PlayerActivityDAO struct {
+	BaseDAO[models.PlayerActivityHour]
+}
+
+func NewPlayerActivityDAO(db *gorm.DB) *PlayerActivityDAO {
+	return &PlayerActivityDAO{
+		BaseDAO: *NewBaseDAO[models.PlayerActivityHour](db),
+	}
+}
+
+// LeaderboardEntry 排行榜条目，Value的含义由排行榜类型决定
+type LeaderboardEntry struct {
+	UID      string  `json:"uid"`
+	Nickname string  `json:"nickname"`
+	Value    float64 `json:"value"`
+}
+
+// PlayerActivitySummary 玩家在一段时间内的活跃汇总
+type PlayerActivitySummary struct {
+	UID      string `json:"uid"`
+	Nickname string `json:"nickname"`
+	PlayTime int64  `json:"playTime"`
+	Deaths   int    `json:"deaths"`
+	MaxDays  int    `json:"maxDays"`
+}
+
+// HourlyPlayTime 某个整点的在线时长
+type HourlyPlayTime struct {
+	Hour    int64 `json:"hour"`
+	Seconds int64 `json:"seconds"`
+}
+
+// RecordPlayTime 增加玩家在某个整点的在线时长
+func (d *PlayerActivityDAO) RecordPlayTime(roomID int, uid string, hour, seconds int64) error {
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "room_id"}, {Name: "uid"}, {Name: "hour"}},
+		DoUpdates: clause.Assignments(map[string]any{"seconds": gorm.Expr("seconds + ?", seconds)}),
+	}).Create(&models.PlayerActivityHour{
+		RoomID:  roomID,
+		UID:     uid,
+		Hour:    hour,
+		Seconds: seconds,
+	}).Error
+}
+
+// RecordStats 记录玩家在某个整点的死亡次数和存活天数，天数取最大值
+func (d *PlayerActivityDAO) RecordStats(roomID int, uid string, hour int64, days, deaths int) error {
+	return d.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "room_id"}, {Name: "uid"}, {Name: "hour"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"deaths": gorm.Expr("deaths + ?", deaths),
+			"days":   gorm.Expr("MAX(days, ?)", days),
+		}),
+	}).Create(&models.PlayerActivityHour{
+		RoomID: roomID,
+		UID:    uid,
+		Hour:   hour,
+		Deaths: deaths,
+		Days:   days,
+	}).Error
+}
+
+// RecordRoomPeak 记录房间在某个整点的最高在线人数
+func (d *PlayerActivityDAO) RecordRoomPeak(roomID int, hour int64, players int) error {
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "room_id"}, {Name: "hour"}},
+		DoUpdates: clause.Assignments(map[string]any{"peak_players": gorm.Expr("MAX(peak_players, ?)", players)}),
+	}).Create(&models.RoomActivityHour{
+		RoomID:      roomID,
+		Hour:        hour,
+		PeakPlayers: players,
+	}).Error
+}
+
+// activityQuery 按时间范围筛选，roomID为0时不筛选房间
+func (d *PlayerActivityDAO) activityQuery(table string, roomID int, start, end int64) *gorm.DB {
+	query := d.db.Table(table+" AS a").Where("a.hour >= ? AND a.hour < ?", start, end)
+	if roomID != 0 {
+		query = query.Where("a.room_id = ?", roomID)
+	}
+	return query
+}
+
+// GetLeaderboard 获取排行榜，metric为playtime、deaths或days
+func (d *PlayerActivityDAO) GetLeaderboard(metric string, roomID int, start, end int64, limit int) ([]LeaderboardEntry, error) {
+	var value string
+	switch metric {
+	case "playtime":
+		value = "SUM(a.seconds)"
+	case "deaths":
+		value = "SUM(a.deaths)"
+	case "days":
+		value = "MAX(a.days)"
+	default:
+		return nil, errors.New("排行榜类型错误")
+	}
+
+	entries := []LeaderboardEntry{}
+	err := d.activityQuery("player_activity_hours", roomID, start, end).
+		Select("a.uid AS uid, COALESCE(p.nickname, '') AS nickname, " + value + " AS value").
+		Joins("LEFT JOIN players p ON p.uid = a.uid").
+		Group("a.uid").
+		Having(value + " > 0").
+		Order("value DESC").
+		Limit(limit).
+		Scan(&entries).Error
+
+	return entries, err
+}
+
+// GetHourlyPlayTime 获取每个整点的总在线时长
+func (d *PlayerActivityDAO) GetHourlyPlayTime(roomID int, start, end int64) ([]HourlyPlayTime, error) {
+	hours := []HourlyPlayTime{}
+	err := d.activityQuery("player_activity_hours", roomID, start, end).
+		Select("a.hour AS hour, SUM(a.seconds) AS seconds").
+		Group("a.hour").
+		Scan(&hours).Error
+
+	return hours, err
+}
+
+// GetActivitySummaries 获取每个玩家的活跃汇总，按在线时长降序
+func (d *PlayerActivityDAO) GetActivitySummaries(roomID int, start, end int64) ([]PlayerActivitySummary, error) {
+	summaries := []PlayerActivitySummary{}
+	err := d.activityQuery("player_activity_hours", roomID, start, end).
+		Select("a.uid AS uid, COALESCE(p.nickname, '') AS nickname, SUM(a.seconds) AS play_time, SUM(a.deaths) AS deaths, MAX(a.days) AS max_days").
+		Joins("LEFT JOIN players p ON p.uid = a.uid").
+		Group("a.uid").
+		Order("play_time DESC").
+		Scan(&summaries).Error
+
+	return summaries, err
+}
+
+// GetNewPlayers 获取第一次出现在时间范围内的玩家
+func (d *PlayerActivityDAO) GetNewPlayers(roomID int, start, end int64) ([]string, error) {
+	query := d.db.Table("player_activity_hours").Select("uid")
+	if roomID != 0 {
+		query = query.Where("room_id = ?", roomID)
+	}
+
+	uids := []string{}
+	err := query.Group("uid").Having("MIN(hour) >= ? AND MIN(hour) < ?", start, end).Scan(&uids).Error
+
+	return uids, err
+}
+
+// GetPeakPlayers 获取时间范围内的最高在线人数，roomID为0时为所有房间同一整点的人数之和
+func (d *PlayerActivityDAO) GetPeakPlayers(roomID int, start, end int64) (*models.RoomActivityHour, error) {
+	var peak models.RoomActivityHour
+	err := d.activityQuery("room_activity_hours", roomID, start, end).
+		Select("a.hour AS hour, SUM(a.peak_players) AS peak_players").
+		Group("a.hour").
+		Order("peak_players DESC").
+		Limit(1).
+		Scan(&peak).Error
+	peak.RoomID = roomID
+
+	return &peak, err
+}
+
+// DeleteActivityBefore 删除过期的活跃记录
+func (d *PlayerActivityDAO) DeleteActivityBefore(hour int64) error {
+	if err := d.db.Where("hour < ?", hour).Delete(&models.PlayerActivityHour{}).Error; err != nil {
+		return err
+	}
+	return d.db.Where("hour < ?", hour).Delete(&models.RoomActivityHour{}).Error
+}
+
+// GetReports 获取房间的活跃报告，不返回报告内容
+func (d *PlayerActivityDAO) GetReports(roomID int) (*[]models.ActivityReport, error) {
+	var reports []models.ActivityReport
+	err := d.db.Omit("data").Where("room_id = ?", roomID).Order("period_start desc").Find(&reports).Error
+
+	return &reports, err
+}
+
+func (d *PlayerActivityDAO) GetReportByID(id int) (*models.ActivityReport, error) {
+	var report models.ActivityReport
+	err := d.db.Where("id = ?", id).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &report, nil
+	}
+	return &report, err
+}
+
+func (d *PlayerActivityDAO) GetReportByPeriod(roomID int, start, end int64) (*models.ActivityReport, error) {
+	var report models.ActivityReport
+	err := d.db.Where("room_id = ? AND period_start = ? AND period_end = ?", roomID, start, end).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &report, nil
+	}
+	return &report, err
+}
+
+// SaveReport 保存活跃报告，同一房间同一周期的报告会被覆盖
+func (d *PlayerActivityDAO) SaveReport(report *models.ActivityReport) error {
+	return d.db.Save(report).Error
+}
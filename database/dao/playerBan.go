@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"dst-management-platform-api/database/models"
+
+	"gorm.io/gorm"
+)
+
+type PlayerBanDAO struct {
+	BaseDAO[models.PlayerBan]
+}
+
+func NewPlayerBanDAO(db *gorm.DB) *PlayerBanDAO {
+	return &PlayerBanDAO{
+		BaseDAO: *NewBaseDAO[models.PlayerBan](db),
+	}
+}
+
+// GetBansByRoomID 获取房间的封禁记录，all为false时只返回生效中的封禁
+func (d *PlayerBanDAO) GetBansByRoomID(roomID int, all bool) (*[]models.PlayerBan, error) {
+	var bans []models.PlayerBan
+	query := d.db.Where("room_id = ?", roomID)
+	if !all {
+		query = query.Where("active = ?", true)
+	}
+	err := query.Order("id desc").Find(&bans).Error
+
+	return &bans, err
+}
+
+func (d *PlayerBanDAO) GetActiveBan(roomID int, uid string) (*[]models.PlayerBan, error) {
+	var bans []models.PlayerBan
+	err := d.db.Where("room_id = ? AND uid = ? AND active = ?", roomID, uid, true).Find(&bans).Error
+
+	return &bans, err
+}
+
+// GetExpiredBans 获取已到期但还没有解封的记录
+func (d *PlayerBanDAO) GetExpiredBans(now int64) (*[]models.PlayerBan, error) {
+	var bans []models.PlayerBan
+	err := d.db.Where("active = ? AND expires_at > 0 AND expires_at <= ?", true, now).Find(&bans).Error
+
+	return &bans, err
+}
+
+// CountBansByRoomAndUID 统计房间中某个玩家的封禁记录数，包括已解封的
+func (d *PlayerBanDAO) CountBansByRoomAndUID(roomID int, uid string) (int64, error) {
+	return d.Count("room_id = ? AND uid = ?", roomID, uid)
+}
+
+// LiftBans 解封
+func (d *PlayerBanDAO) LiftBans(ids []int, liftedBy string, now int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return d.db.Model(&models.PlayerBan{}).Where("id IN ?", ids).Updates(map[string]any{
+		"active":    false,
+		"lifted_at": now,
+		"lifted_by": liftedBy,
+	}).Error
+}
@@ -24,6 +24,36 @@ func (d *RoomDAO) CreateRoom(room *models.Room) (*models.Room, error) {
 	return room, err
 }
 
+// CreateRoomWithWorlds 在同一个事务中创建房间、世界和房间设置
+func (d *RoomDAO) CreateRoomWithWorlds(room *models.Room, worlds []models.World, roomSetting *models.RoomSetting) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(room).Error; err != nil {
+			return err
+		}
+		for index := range worlds {
+			worlds[index].RoomID = room.ID
+			if err := tx.Create(&worlds[index]).Error; err != nil {
+				return err
+			}
+		}
+		roomSetting.RoomID = room.ID
+		return tx.Create(roomSetting).Error
+	})
+}
+
+// DeleteRoomWithWorlds 在同一个事务中删除房间、世界和房间设置
+func (d *RoomDAO) DeleteRoomWithWorlds(roomID int) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("room_id = ?", roomID).Delete(&models.World{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("room_id = ?", roomID).Delete(&models.RoomSetting{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", roomID).Delete(&models.Room{}).Error
+	})
+}
+
 func (d *RoomDAO) UpdateRoom(room *models.Room) error {
 	err := d.db.Save(room).Error
 	return err
@@ -142,3 +172,11 @@ func (d *RoomDAO) GetRoomBasic() (*[]RoomBasic, error) {
 
 	return &roomBasics, nil
 }
+
+// GetMasterPorts 获取所有房间已使用的主节点端口
+func (d *RoomDAO) GetMasterPorts() ([]int, error) {
+	var ports []int
+	err := d.db.Model(&models.Room{}).Pluck("master_port", &ports).Error
+
+	return ports, err
+}
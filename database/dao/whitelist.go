@@ -0,0 +1,83 @@
+package dao
+
+import (
+	"dst-management-platform-api/database/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type WhitelistDAO struct {
+	BaseDAO[models.WhitelistRequest]
+}
+
+func NewWhitelistDAO(db *gorm.DB) *WhitelistDAO {
+	return &WhitelistDAO{
+		BaseDAO: *NewBaseDAO[models.WhitelistRequest](db),
+	}
+}
+
+func (d *WhitelistDAO) GetInvitesByRoomID(roomID int) (*[]models.WhitelistInvite, error) {
+	var invites []models.WhitelistInvite
+	err := d.db.Where("room_id = ?", roomID).Order("id desc").Find(&invites).Error
+
+	return &invites, err
+}
+
+func (d *WhitelistDAO) GetInviteByCode(code string) (*models.WhitelistInvite, error) {
+	var invite models.WhitelistInvite
+	err := d.db.Where("code = ?", code).First(&invite).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &invite, nil
+	}
+	return &invite, err
+}
+
+func (d *WhitelistDAO) CreateInvite(invite *models.WhitelistInvite) error {
+	return d.db.Create(invite).Error
+}
+
+func (d *WhitelistDAO) DeleteInvite(roomID, id int) error {
+	return d.db.Where("room_id = ? AND id = ?", roomID, id).Delete(&models.WhitelistInvite{}).Error
+}
+
+// UseInvite 邀请码使用次数加一，次数已用完时返回false
+func (d *WhitelistDAO) UseInvite(id int) (bool, error) {
+	result := d.db.Model(&models.WhitelistInvite{}).
+		Where("id = ? AND (max_uses = 0 OR uses < max_uses)", id).
+		UpdateColumn("uses", gorm.Expr("uses + ?", 1))
+
+	return result.RowsAffected == 1, result.Error
+}
+
+// GetRequestsByRoomID 获取房间的白名单申请，status为空时返回所有申请
+func (d *WhitelistDAO) GetRequestsByRoomID(roomID int, status string) (*[]models.WhitelistRequest, error) {
+	var requests []models.WhitelistRequest
+	query := d.db.Where("room_id = ?", roomID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("id desc").Find(&requests).Error
+
+	return &requests, err
+}
+
+func (d *WhitelistDAO) GetRequestsByIDs(roomID int, ids []int) (*[]models.WhitelistRequest, error) {
+	var requests []models.WhitelistRequest
+	err := d.db.Where("room_id = ? AND id IN ?", roomID, ids).Find(&requests).Error
+
+	return &requests, err
+}
+
+func (d *WhitelistDAO) GetPendingRequest(roomID int, uid string) (*models.WhitelistRequest, error) {
+	var request models.WhitelistRequest
+	err := d.db.Where("room_id = ? AND uid = ? AND status = ?", roomID, uid, models.WhitelistRequestPending).First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &request, nil
+	}
+	return &request, err
+}
+
+func (d *WhitelistDAO) UpdateRequest(request *models.WhitelistRequest) error {
+	return d.db.Save(request).Error
+}
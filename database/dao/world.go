@@ -64,3 +64,19 @@ func (d *WorldDAO) GetWorldsByRoomID(id int) (*[]models.World, error) {
 
 	return &worlds, err
 }
+
+// GetPorts 获取所有世界已使用的端口
+func (d *WorldDAO) GetPorts() ([]int, error) {
+	var worlds []models.World
+	err := d.db.Select("server_port", "master_server_port", "authentication_port").Find(&worlds).Error
+	if err != nil {
+		return []int{}, err
+	}
+
+	var ports []int
+	for _, world := range worlds {
+		ports = append(ports, world.ServerPort, world.MasterServerPort, world.AuthenticationPort)
+	}
+
+	return ports, nil
+}
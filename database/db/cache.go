@@ -26,8 +26,90 @@ var (
 	InternetIP string
 	// ModDownloadExecuting 如果没有模组正在下载(==0)，则执行临时模组文件清理任务 scheduler/global.go ModDownloadClean()
 	ModDownloadExecuting int32
+	// BackupFingerprint 房间最近一次备份时的存档指纹，用于事件备份去重
+	BackupFingerprint = make(map[int]string)
+	// BackupFingerprintMutex 存档指纹锁
+	BackupFingerprintMutex sync.Mutex
+	// BackupSessionState 事件备份记录的房间天数和季节
+	BackupSessionState = make(map[int]SessionState)
+	// BackupSessionStateMutex 房间天数和季节锁
+	BackupSessionStateMutex sync.Mutex
+	// ModImportProgress 模组合集导入进度
+	ModImportProgress = make(map[int]ModImportStatus)
+	// ModImportProgressMutex 模组合集导入进度锁
+	ModImportProgressMutex sync.Mutex
+	// PlayersActivity 玩家最近的坐标和移动时间，用于挂机检测
+	PlayersActivity = make(map[int]map[string]PlayerActivity)
+	// PlayersActivityMutex 玩家活动锁
+	PlayersActivityMutex sync.Mutex
+	// RollbackProgress 回档进度
+	RollbackProgress = make(map[int]RollbackStatus)
+	// RollbackProgressMutex 回档进度锁
+	RollbackProgressMutex sync.Mutex
+	// PlayersJoinedAt 在线玩家的加入时间，用于预留位踢出最近加入的玩家
+	PlayersJoinedAt = make(map[int]map[string]int64)
+	// PlayersJoinedAtMutex 玩家加入时间锁
+	PlayersJoinedAtMutex sync.Mutex
+	// PlayerDeathCounts 每个世界上次采样时玩家的累计死亡次数，用于计算两次采样之间的死亡次数
+	PlayerDeathCounts = make(map[int]PlayerDeathCount)
+	// PlayerDeathCountsMutex 玩家死亡次数锁
+	PlayerDeathCountsMutex sync.Mutex
+	// WhitelistApplyLimit 白名单申请接口每个IP的请求次数和邀请码错误次数
+	WhitelistApplyLimit = make(map[string]WhitelistApplyLimitState)
+	// WhitelistApplyLimitMutex 白名单申请限流锁
+	WhitelistApplyLimitMutex sync.Mutex
 )
 
+type ModImportStatus struct {
+	CollectionID int    `json:"collectionID"`
+	Title        string `json:"title"`
+	Total        int    `json:"total"`
+	Finished     int    `json:"finished"`
+	Current      int    `json:"current"`
+	Succeeded    []int  `json:"succeeded"`
+	Failed       []int  `json:"failed"`
+	Done         bool   `json:"done"`
+	StartedAt    int64  `json:"startedAt"`
+}
+
+type RollbackStatus struct {
+	Name           string `json:"name"`
+	Count          int    `json:"count"`
+	ExpectedCycles int    `json:"expectedCycles"`
+	CurrentCycles  int    `json:"currentCycles"`
+	Verified       bool   `json:"verified"`
+	Error          string `json:"error"`
+	Done           bool   `json:"done"`
+	StartedAt      int64  `json:"startedAt"`
+	FinishedAt     int64  `json:"finishedAt"`
+}
+
+type PlayerDeathCount struct {
+	Session string         `json:"session"` // 世界启动时生成，世界重启后累计次数从0开始
+	Deaths  map[string]int `json:"deaths"`
+}
+
+type WhitelistApplyLimitState struct {
+	WindowStart    int64 `json:"windowStart"`
+	Requests       int   `json:"requests"`
+	FailureStart   int64 `json:"failureStart"`
+	InviteFailures int   `json:"inviteFailures"`
+	LockedUntil    int64 `json:"lockedUntil"`
+}
+
+type SessionState struct {
+	Cycles int    `json:"cycles"`
+	Season string `json:"season"`
+}
+
+type PlayerActivity struct {
+	X         float64 `json:"x"`
+	Z         float64 `json:"z"`
+	LastMoved int64   `json:"lastMoved"`
+	Afk       bool    `json:"afk"`
+	WarnedAt  int64   `json:"warnedAt"` // 接近满员时提示挂机玩家的时间，玩家移动后清零
+}
+
 type PlayerInfo struct {
 	UID      string `json:"uid"`
 	Nickname string `json:"nickname"`
@@ -48,6 +48,21 @@ func CheckTables() {
 		&models.RoomSetting{},
 		&models.GlobalSetting{},
 		&models.UidMap{},
+		&models.BackupPin{},
+		&models.ModVersion{},
+		&models.ModDownloadJob{},
+		&models.ModProfile{},
+		&models.LocalMod{},
+		&models.Player{},
+		&models.PlayerNickname{},
+		&models.PlayerPresence{},
+		&models.PlayerBan{},
+		&models.GlobalPlayerList{},
+		&models.WhitelistInvite{},
+		&models.WhitelistRequest{},
+		&models.PlayerActivityHour{},
+		&models.RoomActivityHour{},
+		&models.ActivityReport{},
 	)
 	if err != nil {
 		logger.Logger.Error("数据库表结构检查失败", "err", err)
@@ -0,0 +1,12 @@
+package models
+
+type BackupPin struct {
+	ID       int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"` // 自增ID
+	RoomID   int    `gorm:"not null;column:room_id;uniqueIndex:idx_backup_pin" json:"roomID"`
+	FileName string `gorm:"not null;column:file_name;uniqueIndex:idx_backup_pin" json:"fileName"`
+	Note     string `gorm:"column:note" json:"note"`
+}
+
+func (BackupPin) TableName() string {
+	return "backup_pins"
+}
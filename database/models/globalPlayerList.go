@@ -0,0 +1,18 @@
+package models
+
+// GlobalPlayerList 平台级名单，合并到每个房间的adminlist.txt、whitelist.txt、blocklist.txt
+type GlobalPlayerList struct {
+	ID            int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"` // 自增ID
+	ListType      string `gorm:"not null;uniqueIndex:idx_global_player_list;column:list_type" json:"listType"`
+	UID           string `gorm:"not null;uniqueIndex:idx_global_player_list;column:uid" json:"uid"`
+	Nickname      string `gorm:"column:nickname" json:"nickname"`
+	Reason        string `gorm:"column:reason" json:"reason"`
+	Rooms         string `gorm:"column:rooms" json:"rooms"`                  // 生效的房间ID，逗号分隔，为空则对所有房间生效
+	ExcludedRooms string `gorm:"column:excluded_rooms" json:"excludedRooms"` // 不生效的房间ID，逗号分隔
+	CreatedBy     string `gorm:"column:created_by" json:"createdBy"`
+	CreatedAt     int64  `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (GlobalPlayerList) TableName() string {
+	return "global_player_lists"
+}
@@ -6,9 +6,10 @@ type GlobalSetting struct {
 	UIDMaintainEnable  bool   `gorm:"column:uid_maintain_enable" json:"UIDMaintainEnable"`
 	SysMetricsEnable   bool   `gorm:"column:sys_metrics_enable" json:"sysMetricsEnable"`
 	SysMetricsSetting  int    `gorm:"column:sys_metrics_setting" json:"sysMetricsSetting"`
-	AutoUpdateEnable   bool   `gorm:"column:auto_update_enable" json:"autoUpdateEnable"`   // 自动更新是否开启
-	AutoUpdateSetting  string `gorm:"column:auto_update_setting" json:"autoUpdateSetting"` // 自动更新时间设置
-	AutoUpdateRestart  bool   `gorm:"column:auto_update_restart" json:"autoUpdateRestart"` // 自动更新后是否重启，按理说要加在Setting中，但是太麻烦了
+	AutoUpdateEnable   bool   `gorm:"column:auto_update_enable" json:"autoUpdateEnable"`     // 自动更新是否开启
+	AutoUpdateSetting  string `gorm:"column:auto_update_setting" json:"autoUpdateSetting"`   // 自动更新时间设置
+	AutoUpdateRestart  bool   `gorm:"column:auto_update_restart" json:"autoUpdateRestart"`   // 自动更新后是否重启，按理说要加在Setting中，但是太麻烦了
+	SteamProfileEnable bool   `gorm:"column:steam_profile_enable" json:"steamProfileEnable"` // 从Steam获取玩家资料
 }
 
 func (GlobalSetting) TableName() string {
@@ -0,0 +1,16 @@
+package models
+
+type LocalMod struct {
+	ID         int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`                       // 自增ID
+	Name       string `gorm:"not null;uniqueIndex:idx_local_mod_version;column:name" json:"name"` // dst/mods下的目录名，也是modoverrides中的key
+	Version    string `gorm:"not null;uniqueIndex:idx_local_mod_version;column:version" json:"version"`
+	Title      string `gorm:"column:title" json:"title"` // modinfo.lua中的name
+	Size       int64  `gorm:"column:size" json:"size"`
+	Current    bool   `gorm:"column:current" json:"current"` // 当前安装的版本
+	UploadedBy string `gorm:"column:uploaded_by" json:"uploadedBy"`
+	CreatedAt  int64  `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (LocalMod) TableName() string {
+	return "local_mods"
+}
@@ -0,0 +1,32 @@
+package models
+
+// 模组下载任务状态
+const (
+	ModDownloadQueued    = "queued"
+	ModDownloadRunning   = "running"
+	ModDownloadSucceeded = "succeeded"
+	ModDownloadFailed    = "failed"
+	ModDownloadCanceled  = "canceled"
+)
+
+type ModDownloadJob struct {
+	ID          int     `gorm:"primaryKey;autoIncrement;column:id" json:"id"` // 自增ID
+	RoomID      int     `gorm:"not null;column:room_id;index" json:"roomID"`
+	ModID       int     `gorm:"not null;column:mod_id" json:"modID"`
+	Name        string  `gorm:"column:name" json:"name"`
+	FileURL     string  `gorm:"column:file_url" json:"fileURL"`
+	Status      string  `gorm:"not null;column:status;index" json:"status"`
+	Progress    float64 `gorm:"column:progress" json:"progress"` // 百分比
+	Bytes       int64   `gorm:"column:bytes" json:"bytes"`
+	TotalBytes  int64   `gorm:"column:total_bytes" json:"totalBytes"`
+	Attempts    int     `gorm:"column:attempts" json:"attempts"`
+	MaxAttempts int     `gorm:"column:max_attempts" json:"maxAttempts"`
+	NextRunAt   int64   `gorm:"column:next_run_at" json:"nextRunAt"` // 重试时间
+	Error       string  `gorm:"column:error" json:"error"`
+	CreatedAt   int64   `gorm:"column:created_at" json:"createdAt"`
+	UpdatedAt   int64   `gorm:"column:updated_at" json:"updatedAt"`
+}
+
+func (ModDownloadJob) TableName() string {
+	return "mod_download_jobs"
+}
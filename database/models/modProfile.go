@@ -0,0 +1,15 @@
+package models
+
+type ModProfile struct {
+	ID          int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"` // 自增ID
+	Name        string `gorm:"not null;uniqueIndex;column:name" json:"name"`
+	Description string `gorm:"column:description" json:"description"`
+	Content     string `gorm:"column:content" json:"content"` // modoverrides.lua
+	CreatedBy   string `gorm:"column:created_by" json:"createdBy"`
+	CreatedAt   int64  `gorm:"column:created_at" json:"createdAt"`
+	UpdatedAt   int64  `gorm:"column:updated_at" json:"updatedAt"`
+}
+
+func (ModProfile) TableName() string {
+	return "mod_profiles"
+}
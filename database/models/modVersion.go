@@ -0,0 +1,17 @@
+package models
+
+type ModVersion struct {
+	RoomID            int    `gorm:"primaryKey;not null;column:room_id" json:"roomID"`
+	ModID             int    `gorm:"primaryKey;not null;column:mod_id" json:"modID"`
+	Name              string `gorm:"column:name" json:"name"`
+	Version           string `gorm:"column:version" json:"version"`
+	FileURL           string `gorm:"column:file_url" json:"fileURL"`
+	LocalTimeUpdated  int64  `gorm:"column:local_time_updated" json:"localTimeUpdated"`
+	ServerTimeUpdated int64  `gorm:"column:server_time_updated" json:"serverTimeUpdated"`
+	Outdated          bool   `gorm:"column:outdated" json:"outdated"`
+	CheckedAt         int64  `gorm:"column:checked_at" json:"checkedAt"` // 检查时间，毫秒时间戳
+}
+
+func (ModVersion) TableName() string {
+	return "mod_versions"
+}
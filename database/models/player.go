@@ -0,0 +1,50 @@
+package models
+
+// Player 玩家目录，每个KU_ UID一条记录
+type Player struct {
+	UID       string `gorm:"primaryKey;not null;column:uid" json:"uid"`
+	Nickname  string `gorm:"column:nickname;index" json:"nickname"` // 最近使用的昵称
+	FirstSeen int64  `gorm:"column:first_seen" json:"firstSeen"`
+	LastSeen  int64  `gorm:"column:last_seen" json:"lastSeen"`
+	PlayTime  int64  `gorm:"column:play_time" json:"playTime"` // 所有房间的总在线时长，单位秒
+	Notes     string `gorm:"column:notes" json:"notes"`
+	Tags      string `gorm:"column:tags" json:"tags"` // 逗号分隔
+	// Steam资料，SteamID从服务器日志的玩家加入记录中获取，WeGame玩家没有SteamID
+	SteamID        string `gorm:"column:steam_id;index" json:"steamID"`
+	SteamName      string `gorm:"column:steam_name" json:"steamName"`
+	SteamAvatar    string `gorm:"column:steam_avatar" json:"steamAvatar"`
+	SteamCreatedAt int64  `gorm:"column:steam_created_at" json:"steamCreatedAt"` // Steam账号创建时间，资料未公开时为0
+	SteamUpdatedAt int64  `gorm:"column:steam_updated_at" json:"steamUpdatedAt"`
+}
+
+func (Player) TableName() string {
+	return "players"
+}
+
+// PlayerNickname 玩家历史昵称
+type PlayerNickname struct {
+	ID        int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UID       string `gorm:"not null;uniqueIndex:idx_player_nickname;column:uid" json:"uid"`
+	Nickname  string `gorm:"not null;uniqueIndex:idx_player_nickname;column:nickname" json:"nickname"`
+	FirstSeen int64  `gorm:"column:first_seen" json:"firstSeen"`
+	LastSeen  int64  `gorm:"column:last_seen" json:"lastSeen"`
+}
+
+func (PlayerNickname) TableName() string {
+	return "player_nicknames"
+}
+
+// PlayerPresence 玩家在各个房间的出现记录
+type PlayerPresence struct {
+	ID        int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UID       string `gorm:"not null;uniqueIndex:idx_player_presence;column:uid" json:"uid"`
+	RoomID    int    `gorm:"not null;uniqueIndex:idx_player_presence;column:room_id" json:"roomID"`
+	Nickname  string `gorm:"column:nickname" json:"nickname"`
+	FirstSeen int64  `gorm:"column:first_seen" json:"firstSeen"`
+	LastSeen  int64  `gorm:"column:last_seen" json:"lastSeen"`
+	PlayTime  int64  `gorm:"column:play_time" json:"playTime"` // 单位秒
+}
+
+func (PlayerPresence) TableName() string {
+	return "player_presences"
+}
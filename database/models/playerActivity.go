@@ -0,0 +1,43 @@
+package models
+
+// PlayerActivityHour 玩家每小时的在线时长、死亡次数和存活天数，用于排行榜和活跃报告
+type PlayerActivityHour struct {
+	ID      int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	RoomID  int    `gorm:"not null;uniqueIndex:idx_player_activity_hour;column:room_id" json:"roomID"`
+	UID     string `gorm:"not null;uniqueIndex:idx_player_activity_hour;index;column:uid" json:"uid"`
+	Hour    int64  `gorm:"not null;uniqueIndex:idx_player_activity_hour;index;column:hour" json:"hour"` // 整点时间戳，单位毫秒
+	Seconds int64  `gorm:"column:seconds" json:"seconds"`                                               // 在线时长，不包括挂机时间
+	Deaths  int    `gorm:"column:deaths" json:"deaths"`
+	Days    int    `gorm:"column:days" json:"days"` // 该小时内采样到的最大存活天数
+}
+
+func (PlayerActivityHour) TableName() string {
+	return "player_activity_hours"
+}
+
+// RoomActivityHour 房间每小时的最高在线人数
+type RoomActivityHour struct {
+	ID          int   `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	RoomID      int   `gorm:"not null;uniqueIndex:idx_room_activity_hour;column:room_id" json:"roomID"`
+	Hour        int64 `gorm:"not null;uniqueIndex:idx_room_activity_hour;column:hour" json:"hour"`
+	PeakPlayers int   `gorm:"column:peak_players" json:"peakPlayers"`
+}
+
+func (RoomActivityHour) TableName() string {
+	return "room_activity_hours"
+}
+
+// ActivityReport 活跃报告，RoomID为0时为所有房间的报告
+type ActivityReport struct {
+	ID          int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	RoomID      int    `gorm:"not null;index;column:room_id" json:"roomID"`
+	PeriodStart int64  `gorm:"not null;column:period_start" json:"periodStart"`
+	PeriodEnd   int64  `gorm:"not null;column:period_end" json:"periodEnd"`
+	Data        string `gorm:"type:text;column:data" json:"data"` // 报告内容json
+	CreatedBy   string `gorm:"column:created_by" json:"createdBy"`
+	CreatedAt   int64  `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (ActivityReport) TableName() string {
+	return "activity_reports"
+}
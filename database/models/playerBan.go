@@ -0,0 +1,19 @@
+package models
+
+type PlayerBan struct {
+	ID        int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"` // 自增ID
+	RoomID    int    `gorm:"not null;index;column:room_id" json:"roomID"`
+	UID       string `gorm:"not null;index;column:uid" json:"uid"`
+	Nickname  string `gorm:"column:nickname" json:"nickname"`
+	Reason    string `gorm:"column:reason" json:"reason"`
+	IssuedBy  string `gorm:"column:issued_by" json:"issuedBy"`
+	CreatedAt int64  `gorm:"column:created_at" json:"createdAt"`
+	ExpiresAt int64  `gorm:"column:expires_at" json:"expiresAt"` // 0为永久封禁
+	Active    bool   `gorm:"column:active;index" json:"active"`
+	LiftedAt  int64  `gorm:"column:lifted_at" json:"liftedAt"`
+	LiftedBy  string `gorm:"column:lifted_by" json:"liftedBy"` // 到期自动解封为system
+}
+
+func (PlayerBan) TableName() string {
+	return "player_bans"
+}
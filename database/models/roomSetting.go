@@ -6,6 +6,8 @@ type RoomSetting struct {
 	BackupSetting             string `gorm:"column:backup_setting" json:"backupSetting"`
 	BackupCleanEnable         bool   `gorm:"column:backup_clean_enable" json:"backupCleanEnable"`
 	BackupCleanSetting        int    `gorm:"column:backup_clean_setting" json:"backupCleanSetting"`
+	BackupRetentionSetting    string `gorm:"column:backup_retention_setting" json:"backupRetentionSetting"`
+	BackupEventSetting        string `gorm:"column:backup_event_setting" json:"backupEventSetting"`
 	RestartEnable             bool   `gorm:"column:restart_enable" json:"restartEnable"`
 	RestartSetting            string `gorm:"column:restart_setting" json:"restartSetting"`
 	AnnounceSetting           string `gorm:"column:announce_setting" json:"announceSetting"`
@@ -17,6 +19,10 @@ type RoomSetting struct {
 	StartType                 string `gorm:"column:start_type" json:"startType"`
 	CustomIP                  string `gorm:"column:custom_ip" json:"customIP"`
 	CustomPort                int    `gorm:"column:custom_port" json:"customPort"`
+	AfkEnable                 bool   `gorm:"column:afk_enable" json:"afkEnable"`
+	AfkSetting                string `gorm:"column:afk_setting" json:"afkSetting"`
+	ReservedSlotEnable        bool   `gorm:"column:reserved_slot_enable" json:"reservedSlotEnable"`
+	ReservedSlotSetting       string `gorm:"column:reserved_slot_setting" json:"reservedSlotSetting"`
 }
 
 func (RoomSetting) TableName() string {
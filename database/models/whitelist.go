@@ -0,0 +1,42 @@
+package models
+
+const (
+	WhitelistRequestPending  = "pending"
+	WhitelistRequestApproved = "approved"
+	WhitelistRequestRejected = "rejected"
+)
+
+// WhitelistInvite 白名单邀请码
+type WhitelistInvite struct {
+	ID        int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"` // 自增ID
+	RoomID    int    `gorm:"not null;index;column:room_id" json:"roomID"`
+	Code      string `gorm:"not null;uniqueIndex;column:code" json:"code"`
+	MaxUses   int    `gorm:"not null;column:max_uses" json:"maxUses"` // 0为不限次数
+	Uses      int    `gorm:"not null;default:0;column:uses" json:"uses"`
+	ExpiresAt int64  `gorm:"column:expires_at" json:"expiresAt"` // 0为永不过期
+	Note      string `gorm:"column:note" json:"note"`
+	CreatedBy string `gorm:"column:created_by" json:"createdBy"`
+	CreatedAt int64  `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (WhitelistInvite) TableName() string {
+	return "whitelist_invites"
+}
+
+// WhitelistRequest 白名单申请，使用有效邀请码的申请会直接通过
+type WhitelistRequest struct {
+	ID         int    `gorm:"primaryKey;autoIncrement;column:id" json:"id"` // 自增ID
+	RoomID     int    `gorm:"not null;index;column:room_id" json:"roomID"`
+	UID        string `gorm:"not null;index;column:uid" json:"uid"`
+	Nickname   string `gorm:"column:nickname" json:"nickname"`
+	Message    string `gorm:"column:message" json:"message"`
+	Code       string `gorm:"column:code" json:"code"`
+	Status     string `gorm:"not null;index;column:status" json:"status"`
+	CreatedAt  int64  `gorm:"column:created_at" json:"createdAt"`
+	ReviewedBy string `gorm:"column:reviewed_by" json:"reviewedBy"`
+	ReviewedAt int64  `gorm:"column:reviewed_at" json:"reviewedAt"`
+}
+
+func (WhitelistRequest) TableName() string {
+	return "whitelist_requests"
+}
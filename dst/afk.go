@@ -0,0 +1,76 @@
+package dst
+
+import (
+	"dst-management-platform-api/utils"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AfkSetting 挂机检测设置
+type AfkSetting struct {
+	Window        int    `json:"window"`        // 超过多少分钟没有移动视为挂机
+	KickEnable    bool   `json:"kickEnable"`    // 接近满员时踢出挂机玩家
+	KickFreeSlots int    `json:"kickFreeSlots"` // 剩余位置不多于该值时视为接近满员
+	WarnMessage   string `json:"warnMessage"`   // 踢出前对玩家的提示
+	WarnGrace     int    `json:"warnGrace"`     // 提示后多少秒仍未移动则踢出
+}
+
+// ParseAfkSetting 解析挂机检测设置，为空时使用默认值
+func ParseAfkSetting(afkSetting string) (AfkSetting, error) {
+	setting := AfkSetting{
+		Window:    10,
+		WarnGrace: 60,
+	}
+	if afkSetting == "" {
+		return setting, nil
+	}
+	if err := json.Unmarshal([]byte(afkSetting), &setting); err != nil {
+		return setting, err
+	}
+	if setting.Window <= 0 || setting.KickFreeSlots < 0 || setting.WarnGrace < 0 {
+		return setting, fmt.Errorf("挂机检测设置错误")
+	}
+
+	return setting, nil
+}
+
+type PlayerCoordinate struct {
+	X float64 `json:"x"`
+	Z float64 `json:"z"`
+}
+
+// playerCoordinatesLua 输出当前世界所有玩家的坐标，格式为uid,x,z;uid,x,z
+const playerCoordinatesLua = `(function() local r = {} for _, p in ipairs(AllPlayers) do local x, y, z = p.Transform:GetWorldPosition() ` +
+	`table.insert(r, p.userid .. ',' .. math.floor(x) .. ',' .. math.floor(z)) end return table.concat(r, ';') end)()`
+
+// playerCoordinates 获取所有运行中的世界的玩家坐标，与playerPosition一样通过控制台输出到日志后读取
+func (g *Game) playerCoordinates() map[string]PlayerCoordinate {
+	coordinates := make(map[string]PlayerCoordinate)
+
+	for _, world := range g.worldSaveData {
+		identifier := fmt.Sprintf("DMP_PLAYER_COORDINATES_%d", time.Now().UnixNano())
+		logPath := fmt.Sprintf("%s/server_log.txt", world.worldPath)
+		out, err := utils.ScreenCMDOutput(playerCoordinatesLua, identifier, world.screenName, logPath)
+		if err != nil || out == "" {
+			continue
+		}
+
+		for _, record := range strings.Split(out, ";") {
+			fields := strings.Split(record, ",")
+			if len(fields) != 3 {
+				continue
+			}
+			x, errX := strconv.ParseFloat(fields[1], 64)
+			z, errZ := strconv.ParseFloat(fields[2], 64)
+			if errX != nil || errZ != nil {
+				continue
+			}
+			coordinates[fields[0]] = PlayerCoordinate{X: x, Z: z}
+		}
+	}
+
+	return coordinates
+}
@@ -0,0 +1,330 @@
+package dst
+
+import (
+	"crypto/sha256"
+	"dst-management-platform-api/database/db"
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BackupRetention 备份保留策略，各项为0表示不启用
+type BackupRetention struct {
+	Days    int `json:"days"`    // 保留最近N天内的所有备份
+	Hourly  int `json:"hourly"`  // 保留最近N个小时，每小时最新的一个备份
+	Daily   int `json:"daily"`   // 保留最近N天，每天最新的一个备份
+	Weekly  int `json:"weekly"`  // 保留最近N周，每周最新的一个备份
+	Monthly int `json:"monthly"` // 保留最近N个月，每月最新的一个备份
+	Quota   int `json:"quota"`   // 备份总大小上限，单位MB
+}
+
+// DefaultBackupRetentionSetting 新建房间的备份保留策略，默认仅按天数清理
+const DefaultBackupRetentionSetting = "{\"hourly\":0,\"daily\":0,\"weekly\":0,\"monthly\":0,\"quota\":0}"
+
+// ParseBackupRetention 解析备份保留策略，retentionSetting为空时仅按天数清理
+func ParseBackupRetention(days int, retentionSetting string) (BackupRetention, error) {
+	retention := BackupRetention{}
+	if retentionSetting != "" {
+		err := json.Unmarshal([]byte(retentionSetting), &retention)
+		if err != nil {
+			return BackupRetention{}, err
+		}
+	}
+	retention.Days = days
+
+	if retention.Days < 0 || retention.Hourly < 0 || retention.Daily < 0 || retention.Weekly < 0 || retention.Monthly < 0 || retention.Quota < 0 {
+		return BackupRetention{}, fmt.Errorf("备份保留策略错误")
+	}
+
+	return retention, nil
+}
+
+func (r BackupRetention) enabled() bool {
+	return r.Days > 0 || r.Hourly > 0 || r.Daily > 0 || r.Weekly > 0 || r.Monthly > 0
+}
+
+type BackupCleanItem struct {
+	BackupFile
+	Reason string `json:"reason"` // retention: 不在保留策略内 quota: 超出容量限制
+}
+
+// planBackupClean 计算需要清理的备份，锁定的备份和最新的备份永远不会被清理
+func planBackupClean(backups []BackupFile, retention BackupRetention, pinned []string, now time.Time) []BackupCleanItem {
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].TimeStamp > backups[j].TimeStamp
+	})
+
+	keep := make(map[string]bool)
+	for i, backup := range backups {
+		if i == 0 || utils.Contains(pinned, backup.FileName) {
+			keep[backup.FileName] = true
+		}
+	}
+
+	if !retention.enabled() {
+		for _, backup := range backups {
+			keep[backup.FileName] = true
+		}
+	}
+
+	if retention.Days > 0 {
+		cutoff := now.AddDate(0, 0, -retention.Days).UnixMilli()
+		for _, backup := range backups {
+			if int64(backup.TimeStamp) >= cutoff {
+				keep[backup.FileName] = true
+			}
+		}
+	}
+
+	// 按时间段分组，每组保留最新的一个，共保留count组
+	keepPeriods := func(count int, period func(t time.Time) string) {
+		if count <= 0 {
+			return
+		}
+		periods := make(map[string]bool)
+		for _, backup := range backups {
+			p := period(time.UnixMilli(int64(backup.TimeStamp)))
+			if periods[p] {
+				continue
+			}
+			if len(periods) >= count {
+				break
+			}
+			periods[p] = true
+			keep[backup.FileName] = true
+		}
+	}
+	keepPeriods(retention.Hourly, func(t time.Time) string { return t.Format("2006010215") })
+	keepPeriods(retention.Daily, func(t time.Time) string { return t.Format("20060102") })
+	keepPeriods(retention.Weekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+	keepPeriods(retention.Monthly, func(t time.Time) string { return t.Format("200601") })
+
+	var items []BackupCleanItem
+	var keptSize int64
+	for _, backup := range backups {
+		if keep[backup.FileName] {
+			keptSize += backup.Size
+		} else {
+			items = append(items, BackupCleanItem{BackupFile: backup, Reason: "retention"})
+		}
+	}
+
+	// 超出容量限制时，从最旧的备份开始清理
+	if retention.Quota > 0 {
+		quota := int64(retention.Quota) * 1024 * 1024
+		for i := len(backups) - 1; i > 0 && keptSize > quota; i-- {
+			backup := backups[i]
+			if !keep[backup.FileName] || utils.Contains(pinned, backup.FileName) {
+				continue
+			}
+			keep[backup.FileName] = false
+			keptSize -= backup.Size
+			items = append(items, BackupCleanItem{BackupFile: backup, Reason: "quota"})
+		}
+	}
+
+	if len(items) == 0 {
+		return []BackupCleanItem{}
+	}
+
+	return items
+}
+
+func (g *Game) previewBackupClean(retention BackupRetention, pinned []string) ([]BackupCleanItem, error) {
+	backups, err := g.getBackups()
+	if err != nil {
+		return []BackupCleanItem{}, err
+	}
+
+	return planBackupClean(backups, retention, pinned, time.Now()), nil
+}
+
+func (g *Game) cleanBackups(retention BackupRetention, pinned []string) (int, error) {
+	items, err := g.previewBackupClean(retention, pinned)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, item := range items {
+		err = utils.RemoveFile(fmt.Sprintf("%s/backup/%d/%s", utils.DmpFiles, g.room.ID, item.FileName))
+		if err != nil {
+			logger.Logger.Error("删除备份文件失败", "err", err, "file", item.FileName)
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+const (
+	BackupEventUpdate  = "update"
+	BackupEventReset   = "reset"
+	BackupEventRestore = "restore"
+	BackupEventMod     = "mod"
+	BackupEventDay     = "day"
+	BackupEventSeason  = "season"
+)
+
+// BackupEvent 事件备份设置
+type BackupEvent struct {
+	Update       bool `json:"update"`       // 游戏更新前
+	Reset        bool `json:"reset"`        // 重置世界前
+	Restore      bool `json:"restore"`      // 恢复备份前
+	Mod          bool `json:"mod"`          // 启用、禁用模组前
+	DayMultiple  int  `json:"dayMultiple"`  // 天数达到N的倍数时
+	SeasonChange bool `json:"seasonChange"` // 季节变化时
+}
+
+// ParseBackupEvent 解析事件备份设置，为空时不启用任何事件备份
+func ParseBackupEvent(eventSetting string) (BackupEvent, error) {
+	backupEvent := BackupEvent{}
+	if eventSetting == "" {
+		return backupEvent, nil
+	}
+	err := json.Unmarshal([]byte(eventSetting), &backupEvent)
+
+	return backupEvent, err
+}
+
+func (e BackupEvent) enabled(event string) bool {
+	switch event {
+	case BackupEventUpdate:
+		return e.Update
+	case BackupEventReset:
+		return e.Reset
+	case BackupEventRestore:
+		return e.Restore
+	case BackupEventMod:
+		return e.Mod
+	case BackupEventDay:
+		return e.DayMultiple > 0
+	case BackupEventSeason:
+		return e.SeasonChange
+	default:
+		return false
+	}
+}
+
+// clusterFingerprint 计算存档指纹，忽略日志和dmp.json，用于判断存档是否有变化
+func (g *Game) clusterFingerprint() (string, error) {
+	hash := sha256.New()
+
+	err := filepath.WalkDir(g.clusterPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			// 世界目录下的backup为历史日志
+			if name == "backup" && path != g.clusterPath {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if name == "dmp.json" || strings.HasSuffix(name, ".log") || name == "server_log.txt" || name == "server_chat_log.txt" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(hash, "%s|%d|%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (g *Game) saveBackupFingerprint() {
+	fingerprint, err := g.clusterFingerprint()
+	if err != nil {
+		logger.Logger.Warn("计算存档指纹失败", "err", err)
+		return
+	}
+
+	db.BackupFingerprintMutex.Lock()
+	defer db.BackupFingerprintMutex.Unlock()
+	db.BackupFingerprint[g.room.ID] = fingerprint
+}
+
+// eventBackup 事件触发的备份，未开启对应事件或存档没有变化时跳过
+func (g *Game) eventBackup(event string) error {
+	backupEvent, err := ParseBackupEvent(g.setting.BackupEventSetting)
+	if err != nil {
+		return err
+	}
+	if !backupEvent.enabled(event) {
+		return nil
+	}
+
+	fingerprint, err := g.clusterFingerprint()
+	if err != nil {
+		return err
+	}
+
+	db.BackupFingerprintMutex.Lock()
+	unchanged := db.BackupFingerprint[g.room.ID] == fingerprint
+	db.BackupFingerprintMutex.Unlock()
+	if unchanged {
+		logger.Logger.Info("存档没有变化，跳过事件备份", "room", g.room.ID, "event", event)
+		return nil
+	}
+
+	logger.Logger.Info("执行事件备份", "room", g.room.ID, "event", event)
+
+	return g.backup()
+}
+
+// sessionEventBackup 根据天数和季节变化触发备份
+func (g *Game) sessionEventBackup() error {
+	backupEvent, err := ParseBackupEvent(g.setting.BackupEventSetting)
+	if err != nil {
+		return err
+	}
+	if !backupEvent.enabled(BackupEventDay) && !backupEvent.enabled(BackupEventSeason) {
+		return nil
+	}
+
+	sessionInfo := g.sessionInfo()
+	if sessionInfo.Cycles < 0 {
+		return nil
+	}
+
+	db.BackupSessionStateMutex.Lock()
+	lastState, ok := db.BackupSessionState[g.room.ID]
+	db.BackupSessionState[g.room.ID] = db.SessionState{
+		Cycles: sessionInfo.Cycles,
+		Season: sessionInfo.Season,
+	}
+	db.BackupSessionStateMutex.Unlock()
+
+	// 首次记录或回档、重置后不触发
+	if !ok || sessionInfo.Cycles <= lastState.Cycles {
+		return nil
+	}
+
+	if backupEvent.enabled(BackupEventDay) && sessionInfo.Cycles/backupEvent.DayMultiple > lastState.Cycles/backupEvent.DayMultiple {
+		return g.eventBackup(BackupEventDay)
+	}
+
+	if backupEvent.enabled(BackupEventSeason) && sessionInfo.Season != lastState.Season {
+		return g.eventBackup(BackupEventSeason)
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package dst
 
 import (
+	"context"
 	"dst-management-platform-api/logger"
 	"dst-management-platform-api/utils"
 )
@@ -9,6 +10,12 @@ import (
 func (g *Game) SaveAll() error {
 	var err error
 
+	// 预留位VIP名单合并到白名单
+	err = g.applyReservedSlots()
+	if err != nil {
+		return err
+	}
+
 	// cluster
 	err = g.createRoom()
 	if err != nil {
@@ -81,9 +88,74 @@ func (g *Game) SessionInfo() *RoomSessionInfo {
 	return g.sessionInfo()
 }
 
-// DownloadMod 下载模组
-func (g *Game) DownloadMod(id int, fileURL string) (error, int64) {
-	return g.downloadMod(id, fileURL)
+// ValidateMods 校验已启用模组的依赖和兼容性
+func (g *Game) ValidateMods(worldID int) (*ModValidation, error) {
+	return g.validateMods(worldID)
+}
+
+// ExportModManifest 导出已启用的模组清单
+func (g *Game) ExportModManifest(worldID int, withConfig bool) (*ModManifest, error) {
+	return g.exportModManifest(worldID, withConfig)
+}
+
+// ImportMods 下载并启用模组，返回给handler函数保存到数据库
+func (g *Game) ImportMods(items []WorkshopItem, worldIDs []int, overrides map[int]map[string]any, progress func(item WorkshopItem, err error)) error {
+	return g.importMods(items, worldIDs, overrides, progress)
+}
+
+// DownloadModContext 下载模组，支持取消和进度回调
+func (g *Game) DownloadModContext(ctx context.Context, id int, fileURL string, progress ModDownloadProgress) (error, int64) {
+	return g.downloadModContext(ctx, id, fileURL, progress)
+}
+
+// GetModOverrides 获取房间或世界的modoverrides.lua内容
+func (g *Game) GetModOverrides(worldID int) (string, error) {
+	return g.getModOverrides(worldID)
+}
+
+// ApplyModOverrides 覆盖模组配置，返回给handler函数保存到数据库
+func (g *Game) ApplyModOverrides(content string, worldIDs []int) error {
+	return g.applyModOverrides(content, worldIDs)
+}
+
+// GetModPath 获取已下载模组的目录，以及是否为ugc模组
+func (g *Game) GetModPath(modID int) (string, bool, error) {
+	return g.getModPath(modID)
+}
+
+// GetLevelDataView 获取世界配置及所有已知配置项
+func (g *Game) GetLevelDataView(worldID int) (*LevelDataView, error) {
+	return g.getLevelDataView(worldID)
+}
+
+// PatchLevelData 修改世界配置，返回校验失败的配置项，返回给handler函数保存到数据库
+func (g *Game) PatchLevelData(worldID int, overrides map[string]string, force bool) ([]LevelDataOverrideError, error) {
+	return g.patchLevelData(worldID, overrides, force)
+}
+
+// GetClientMods 获取房间已启用的模组及玩家是否需要订阅
+func (g *Game) GetClientMods() ([]ClientMod, error) {
+	return g.getClientMods()
+}
+
+// LocalModEnable 启用本地模组，返回给handler函数保存到数据库
+func (g *Game) LocalModEnable(name string) error {
+	return g.localModEnable(name)
+}
+
+// LocalModDisable 禁用本地模组，返回给handler函数保存到数据库
+func (g *Game) LocalModDisable(name string) error {
+	return g.localModDisable(name)
+}
+
+// GetEnabledLocalMods 获取已启用的本地模组
+func (g *Game) GetEnabledLocalMods(worldID int) ([]string, error) {
+	return g.getEnabledLocalMods(worldID)
+}
+
+// IsLocalModEnabled 房间是否启用了本地模组
+func (g *Game) IsLocalModEnabled(name string) (bool, error) {
+	return g.isLocalModEnabled(name)
 }
 
 // GetDownloadedMods 获取已经下载的模组
@@ -101,9 +173,19 @@ func (g *Game) GetModConfigureOptionsValues(worldID, modID int, ugc bool) (*ModO
 	return g.getModConfigureOptionsValues(worldID, modID, ugc)
 }
 
-// ModConfigureOptionsValuesChange 修改mod配置，返回给handler函数保存到数据库
-func (g *Game) ModConfigureOptionsValuesChange(worldID, modID int, modConfig *ModORConfig) error {
-	return g.modConfigureOptionsValuesChange(worldID, modID, modConfig)
+// ModConfigureOptionsValuesChange 修改mod配置，返回校验失败的配置项，返回给handler函数保存到数据库
+func (g *Game) ModConfigureOptionsValuesChange(worldID, modID int, modConfig *ModORConfig, force bool) ([]ModOptionError, error) {
+	return g.modConfigureOptionsValuesChange(worldID, modID, modConfig, force)
+}
+
+// ModConfigReset 将模组配置重置为默认值，返回给handler函数保存到数据库
+func (g *Game) ModConfigReset(worldID, modID int) error {
+	return g.modConfigReset(worldID, modID)
+}
+
+// ModConfigDiff 对比模组当前配置和默认配置
+func (g *Game) ModConfigDiff(worldID, modID int) ([]ModOptionDiff, error) {
+	return g.modConfigDiff(worldID, modID)
 }
 
 // ModEnable 启用mod，保存文件，返回给handler函数保存到数据库
@@ -206,6 +288,51 @@ func (g *Game) RemovePlayerList(uid, listType string) error {
 	return g.removePlayerList(uid, listType)
 }
 
+// SetBlocklist 使用生效中的封禁和平台级黑名单重新生成blocklist.txt
+func (g *Game) SetBlocklist(uids, global []string) error {
+	return g.setBlocklist(uids, global)
+}
+
+// LocalBlocklist 获取blocklist.txt中不是由平台级黑名单合并进来的UID
+func (g *Game) LocalBlocklist() []string {
+	return g.localBlocklist()
+}
+
+// BanPlayer 踢出并封禁玩家，duration为0时永久封禁，单位秒
+func (g *Game) BanPlayer(uid string, duration int64) error {
+	return g.banPlayer(uid, duration)
+}
+
+// PlayerAction 对玩家执行踢出、击杀、复活、传送、给予物品等操作
+func (g *Game) PlayerAction(action PlayerAction) error {
+	return g.playerAction(action)
+}
+
+// PlayerState 获取玩家的生命、饥饿、理智、物品栏和装备，在线玩家为实时数据，离线玩家为最后一次存档的数据
+func (g *Game) PlayerState(uid string) (*PlayerState, error) {
+	return g.playerState(uid)
+}
+
+// PlayerCoordinates 获取所有运行中的世界的玩家坐标
+func (g *Game) PlayerCoordinates() map[string]PlayerCoordinate {
+	return g.playerCoordinates()
+}
+
+// SamplePlayerStats 采样玩家存活天数和死亡次数
+func (g *Game) SamplePlayerStats() []PlayerStatSample {
+	return g.samplePlayerStats()
+}
+
+// SteamIDsFromLog 从服务器日志中获取玩家的SteamID，返回以UID为键的SteamID
+func (g *Game) SteamIDsFromLog() map[string]string {
+	return g.steamIDsFromLog()
+}
+
+// ApplyGlobalPlayerLists 将平台级管理员名单和白名单合并到房间名单
+func (g *Game) ApplyGlobalPlayerLists(adminlist, whitelist []string) error {
+	return g.applyGlobalPlayerLists(adminlist, whitelist)
+}
+
 // GetPlayerList 获取三个名单
 func (g *Game) GetPlayerList(listType string) []string {
 	switch listType {
@@ -255,3 +382,53 @@ func (g *Game) GetSnapshot() ([]SnapshotFile, error) {
 func (g *Game) DeleteSnapshot(filename string) error {
 	return g.deleteSnapshot(filename)
 }
+
+// StartRollback 回档到指定的饥荒存档文件，所有世界，校验通过后在后台执行，done在回档结束后调用
+func (g *Game) StartRollback(filename string, done func(*RollbackResult, error)) (*RollbackResult, error) {
+	return g.startRollback(filename, done)
+}
+
+// GetBackupTree 获取备份文件的目录树
+func (g *Game) GetBackupTree(filename string) ([]*BackupTreeNode, error) {
+	return g.getBackupTree(filename)
+}
+
+// ReadBackupFile 读取备份中的文本文件
+func (g *Game) ReadBackupFile(filename, path string) (string, error) {
+	return g.readBackupFile(filename, path)
+}
+
+// ExtractBackupFile 将备份中的单个文件解压到当前存档，修改后需要更新数据库中的房间和世界
+func (g *Game) ExtractBackupFile(filename, path string) error {
+	return g.extractBackupFile(filename, path)
+}
+
+// PreviewBackupClean 预览下次清理会删除的备份文件
+func (g *Game) PreviewBackupClean(retention BackupRetention, pinned []string) ([]BackupCleanItem, error) {
+	return g.previewBackupClean(retention, pinned)
+}
+
+// CleanBackups 按保留策略清理备份文件，返回清理的个数
+func (g *Game) CleanBackups(retention BackupRetention, pinned []string) (int, error) {
+	return g.cleanBackups(retention, pinned)
+}
+
+// EventBackup 事件触发的备份，未开启对应事件或存档没有变化时跳过
+func (g *Game) EventBackup(event string) error {
+	return g.eventBackup(event)
+}
+
+// SessionEventBackup 天数达到设置的倍数或季节变化时备份
+func (g *Game) SessionEventBackup() error {
+	return g.sessionEventBackup()
+}
+
+// CloneTo 将当前房间的存档、模组、名单复制到目标房间
+func (g *Game) CloneTo(target *Game, option CloneOption) error {
+	return g.cloneTo(target, option)
+}
+
+// CheckModUpdates 检查已下载模组是否有更新
+func (g *Game) CheckModUpdates() ([]DownloadedMod, error) {
+	return g.checkModUpdates()
+}
@@ -0,0 +1,507 @@
+package dst
+
+import (
+	"dst-management-platform-api/database/models"
+	"dst-management-platform-api/utils"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/gopher-lua"
+)
+
+// ============== //
+// leveldataoverride.lua / worldgenoverride.lua
+// ============== //
+
+// LevelData leveldataoverride.lua或worldgenoverride.lua的完整内容，overrides以外的字段原样保留
+type LevelData map[string]any
+
+// LevelDataParser Lua世界配置解析器
+type LevelDataParser struct {
+	L *lua.LState
+}
+
+// NewLevelDataParser 创建新的解析器
+func NewLevelDataParser() *LevelDataParser {
+	return &LevelDataParser{
+		L: lua.NewState(),
+	}
+}
+
+func (p *LevelDataParser) close() {
+	if p.L != nil {
+		p.L.Close()
+	}
+}
+
+// Parse 解析世界配置，文件需要return一个表
+func (p *LevelDataParser) Parse(content string) (LevelData, error) {
+	if err := p.L.DoString(content); err != nil {
+		return LevelData{}, err
+	}
+
+	table, ok := p.L.Get(-1).(*lua.LTable)
+	if !ok {
+		return LevelData{}, fmt.Errorf("世界配置格式错误，需要返回一个表")
+	}
+
+	data := LevelData{}
+	table.ForEach(func(key lua.LValue, value lua.LValue) {
+		data[key.String()] = convertLuaValue(value)
+	})
+
+	return data, nil
+}
+
+// ParseLevelData 解析世界配置
+func ParseLevelData(content string) (LevelData, error) {
+	parser := NewLevelDataParser()
+	defer parser.close()
+
+	return parser.Parse(content)
+}
+
+// Location 世界类型，forest或cave，worldgenoverride.lua没有location字段时按preset判断
+func (ld LevelData) Location() string {
+	if location, ok := ld["location"].(string); ok && location != "" {
+		return location
+	}
+	for _, key := range []string{"preset", "id"} {
+		if preset, ok := ld[key].(string); ok && strings.Contains(strings.ToUpper(preset), "CAVE") {
+			return LevelDataLocationCave
+		}
+	}
+
+	return LevelDataLocationForest
+}
+
+// Overrides 获取overrides表，值统一转换为字符串
+func (ld LevelData) Overrides() map[string]string {
+	overrides := make(map[string]string)
+	table, ok := ld["overrides"].(map[string]any)
+	if !ok {
+		return overrides
+	}
+	for key, value := range table {
+		switch v := value.(type) {
+		case string:
+			overrides[key] = v
+		default:
+			overrides[key] = strings.Trim(formatLuaValue(v), "\"")
+		}
+	}
+
+	return overrides
+}
+
+// SetOverride 修改overrides表中的单个配置
+func (ld LevelData) SetOverride(key, value string) {
+	table, ok := ld["overrides"].(map[string]any)
+	if !ok {
+		// 空表会被解析为数组
+		table = make(map[string]any)
+		ld["overrides"] = table
+	}
+	table[key] = value
+}
+
+// ToLuaCode 将世界配置转换为Lua代码
+func (ld LevelData) ToLuaCode() string {
+	return "return " + formatLuaTable(map[string]any(ld), 0)
+}
+
+// formatLuaTable 将Go值格式化为带缩进的Lua表，键按字母排序
+func formatLuaTable(value any, indent int) string {
+	padding := strings.Repeat("  ", indent+1)
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var builder strings.Builder
+		builder.WriteString("{\n")
+		for i, key := range keys {
+			builder.WriteString(fmt.Sprintf("%s%s=%s", padding, formatLuaKey(key), formatLuaTable(v[key], indent+1)))
+			if i != len(keys)-1 {
+				builder.WriteString(",")
+			}
+			builder.WriteString("\n")
+		}
+		builder.WriteString(strings.Repeat("  ", indent) + "}")
+		return builder.String()
+	case []any:
+		if len(v) == 0 {
+			return "{}"
+		}
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatLuaTable(item, indent+1))
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	case string:
+		return quoteLuaString(v)
+	default:
+		return formatLuaValue(v)
+	}
+}
+
+func quoteLuaString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+// ============== //
+// 世界配置项定义
+// ============== //
+
+const (
+	LevelDataLocationForest = "forest"
+	LevelDataLocationCave   = "cave"
+)
+
+type LevelDataOption struct {
+	Key      string   `json:"key"`
+	Group    string   `json:"group"`
+	Label    string   `json:"label"`
+	Values   []string `json:"values"`
+	Default  string   `json:"default"`
+	WorldGen bool     `json:"worldGen"` // 世界生成选项，只在生成新世界时生效
+}
+
+type levelDataOptionDef struct {
+	key      string
+	group    string
+	zh       string
+	en       string
+	values   []string
+	def      string
+	worldGen bool
+}
+
+var (
+	worldSizeValues   = []string{"small", "medium", "default", "huge"}
+	branchingValues   = []string{"never", "least", "default", "most", "random"}
+	loopValues        = []string{"never", "default", "always"}
+	worldgenFrequency = []string{"never", "rare", "uncommon", "default", "often", "mostly", "always", "insane"}
+	spawnRateValues   = []string{"never", "few", "default", "many", "always"}
+	eventFrequency    = []string{"never", "rare", "default", "often", "always"}
+	speedValues       = []string{"never", "veryslow", "slow", "default", "fast", "veryfast"}
+	seasonLength      = []string{"noseason", "veryshortseason", "shortseason", "default", "longseason", "verylongseason", "random"}
+	seasonStartValues = []string{"default", "winter", "spring", "summer", "autumnorspring", "winterorsummer", "random"}
+	dayValues         = []string{"default", "longday", "longdusk", "longnight", "noday", "nodusk", "nonight", "onlyday", "onlydusk", "onlynight"}
+	noneAlwaysValues  = []string{"none", "always"}
+	lethalValues      = []string{"nonlethal", "default"}
+	specialEvents     = []string{"none", "default", "crow_carnival", "hallowed_nights", "winters_feast", "year_of_the_gobbler", "year_of_the_varg", "year_of_the_pig", "year_of_the_carrat", "year_of_the_beefalo", "year_of_the_catcoon", "year_of_the_bunnyman", "year_of_the_dragonfly"}
+)
+
+// 两种世界共用的玩家设置
+var survivorOptionDefs = []levelDataOptionDef{
+	{"ghostenabled", "survivors", "幽灵", "Ghosts", noneAlwaysValues, "always", false},
+	{"ghostsanitydrain", "survivors", "幽灵降低理智", "Ghost Sanity Drain", noneAlwaysValues, "always", false},
+	{"portalresurection", "survivors", "绚丽之门复活", "Resurrect From Portal", noneAlwaysValues, "none", false},
+	{"resettime", "survivors", "无人存活重置时间", "Reset Time", []string{"none", "slow", "default", "fast", "always"}, "default", false},
+	{"spawnmode", "survivors", "出生方式", "Spawn Mode", []string{"fixed", "scatter"}, "fixed", false},
+	{"healthpenalty", "survivors", "复活生命惩罚", "Health Penalty", noneAlwaysValues, "always", false},
+	{"lessdamagetaken", "survivors", "受到伤害减少", "Less Damage Taken", noneAlwaysValues, "none", false},
+	{"temperaturedamage", "survivors", "温度伤害", "Temperature Damage", lethalValues, "default", false},
+	{"hunger", "survivors", "饥饿伤害", "Hunger Damage", lethalValues, "default", false},
+	{"darkness", "survivors", "黑暗伤害", "Darkness Damage", lethalValues, "default", false},
+	{"extrastartingitems", "survivors", "额外起始资源", "Extra Starting Items", []string{"0", "5", "default", "15", "20", "none"}, "default", false},
+	{"seasonalstartingitems", "survivors", "季节起始物品", "Seasonal Starting Items", []string{"never", "default"}, "default", false},
+	{"dropeverythingondespawn", "survivors", "离开时掉落物品", "Drop Items On Disconnect", []string{"default", "always"}, "default", false},
+	{"shadowcreatures", "survivors", "暗影生物", "Shadow Creatures", eventFrequency, "default", false},
+	{"brightmarecreatures", "survivors", "启迪生物", "Enlightenment Monsters", eventFrequency, "default", false},
+	{"regrowth", "world", "再生速度", "Regrowth", speedValues, "default", false},
+	{"basicresource_regrowth", "world", "基础资源再生", "Basic Resource Regrowth", noneAlwaysValues, "none", false},
+	{"specialevent", "world", "活动", "Event", specialEvents, "default", false},
+	{"day", "world", "昼夜长度", "Day Type", dayValues, "default", false},
+	{"weather", "world", "雨", "Rain", eventFrequency, "default", false},
+	{"lightning", "world", "闪电", "Lightning", eventFrequency, "default", false},
+}
+
+var forestOptionDefs = []levelDataOptionDef{
+	{"task_set", "worldgen", "生物群落", "Biomes", []string{"default", "classic"}, "default", true},
+	{"start_location", "worldgen", "出生点", "Spawn Area", []string{"default", "plus", "darkness"}, "default", true},
+	{"world_size", "worldgen", "世界大小", "World Size", worldSizeValues, "default", true},
+	{"branching", "worldgen", "分支", "Branches", branchingValues, "default", true},
+	{"loop", "worldgen", "环形", "Loops", loopValues, "default", true},
+	{"roads", "worldgen", "道路", "Roads", []string{"never", "default"}, "default", true},
+	{"touchstone", "worldgen", "试金石", "Touch Stones", worldgenFrequency, "default", true},
+	{"boons", "worldgen", "骨架", "Failed Survivors", worldgenFrequency, "default", true},
+	{"prefabswaps_start", "worldgen", "开局资源多样性", "Starting Resource Variety", []string{"classic", "default", "highly random"}, "default", true},
+	{"flint", "resources", "燧石", "Flint", worldgenFrequency, "default", true},
+	{"grass", "resources", "草", "Grass", worldgenFrequency, "default", true},
+	{"sapling", "resources", "树苗", "Saplings", worldgenFrequency, "default", true},
+	{"reeds", "resources", "芦苇", "Reeds", worldgenFrequency, "default", true},
+	{"trees", "resources", "树", "Trees", worldgenFrequency, "default", true},
+	{"rock", "resources", "巨石", "Boulders", worldgenFrequency, "default", true},
+	{"flowers", "resources", "花", "Flowers", worldgenFrequency, "default", true},
+	{"mushroom", "resources", "蘑菇", "Mushrooms", worldgenFrequency, "default", true},
+	{"carrot", "resources", "胡萝卜", "Carrots", worldgenFrequency, "default", true},
+	{"berrybush", "resources", "浆果丛", "Berry Bushes", worldgenFrequency, "default", true},
+	{"cactus", "resources", "仙人掌", "Cacti", worldgenFrequency, "default", true},
+	{"ponds", "resources", "池塘", "Ponds", worldgenFrequency, "default", true},
+	{"marshbush", "resources", "尖刺灌木", "Spiky Bushes", worldgenFrequency, "default", true},
+	{"pigs", "creatures", "猪人房", "Pig Houses", worldgenFrequency, "default", true},
+	{"beefalo", "creatures", "皮弗娄牛", "Beefalo", worldgenFrequency, "default", true},
+	{"rabbits", "creatures", "兔子洞", "Rabbit Holes", worldgenFrequency, "default", true},
+	{"moles", "creatures", "鼹鼠洞", "Mole Burrows", worldgenFrequency, "default", true},
+	{"bees", "creatures", "蜂窝", "Bee Hives", worldgenFrequency, "default", true},
+	{"angrybees", "creatures", "杀人蜂窝", "Killer Bee Hives", worldgenFrequency, "default", true},
+	{"tallbirds", "creatures", "高脚鸟", "Tallbirds", worldgenFrequency, "default", true},
+	{"spiders", "creatures", "蜘蛛巢", "Spider Dens", worldgenFrequency, "default", true},
+	{"merm", "creatures", "鱼人房", "Leaky Shacks", worldgenFrequency, "default", true},
+	{"houndmound", "creatures", "猎犬丘", "Hound Mounds", worldgenFrequency, "default", true},
+	{"walrus", "creatures", "海象营地", "MacTusk", worldgenFrequency, "default", true},
+	{"tentacles", "creatures", "触手", "Tentacles", worldgenFrequency, "default", true},
+	{"chess", "creatures", "发条生物", "Clockwork Monsters", worldgenFrequency, "default", true},
+	{"lureplants", "creatures", "食人花", "Lureplants", worldgenFrequency, "default", true},
+	{"autumn", "seasons", "秋天", "Autumn", seasonLength, "default", false},
+	{"winter", "seasons", "冬天", "Winter", seasonLength, "default", false},
+	{"spring", "seasons", "春天", "Spring", seasonLength, "default", false},
+	{"summer", "seasons", "夏天", "Summer", seasonLength, "default", false},
+	{"season_start", "seasons", "起始季节", "Starting Season", seasonStartValues, "default", false},
+	{"frograin", "world", "青蛙雨", "Frog Rain", eventFrequency, "default", false},
+	{"wildfires", "world", "野火", "Wildfires", eventFrequency, "default", false},
+	{"meteorspawner", "world", "流星频率", "Meteor Frequency", eventFrequency, "default", false},
+	{"petrification", "world", "森林石化", "Forest Petrification", []string{"none", "few", "default", "many", "max"}, "default", false},
+	{"hounds", "monsters", "猎犬袭击", "Hound Attacks", eventFrequency, "default", false},
+	{"deerclops", "monsters", "独眼巨鹿", "Deerclops", eventFrequency, "default", false},
+	{"bearger", "monsters", "熊獾", "Bearger", eventFrequency, "default", false},
+	{"goosemoose", "monsters", "麋鹿鹅", "Moose/Goose", eventFrequency, "default", false},
+	{"dragonfly", "monsters", "龙蝇", "Dragonfly", eventFrequency, "default", false},
+	{"krampus", "monsters", "坎普斯", "Krampii", eventFrequency, "default", false},
+	{"liefs", "monsters", "树精守卫", "Treeguards", eventFrequency, "default", false},
+	{"bunnymen_setting", "creatures", "兔人", "Bunnymen", spawnRateValues, "default", false},
+	{"catcoons", "creatures", "浣猫", "Catcoons", spawnRateValues, "default", false},
+	{"perd", "creatures", "火鸡", "Gobblers", spawnRateValues, "default", false},
+	{"butterfly", "creatures", "蝴蝶", "Butterflies", spawnRateValues, "default", false},
+	{"birds", "creatures", "鸟", "Birds", spawnRateValues, "default", false},
+}
+
+var caveOptionDefs = []levelDataOptionDef{
+	{"task_set", "worldgen", "生物群落", "Biomes", []string{"cave_default"}, "cave_default", true},
+	{"start_location", "worldgen", "出生点", "Spawn Area", []string{"caves"}, "caves", true},
+	{"world_size", "worldgen", "世界大小", "World Size", worldSizeValues, "default", true},
+	{"branching", "worldgen", "分支", "Branches", branchingValues, "default", true},
+	{"loop", "worldgen", "环形", "Loops", loopValues, "default", true},
+	{"cave_ponds", "resources", "洞穴池塘", "Cave Ponds", worldgenFrequency, "default", true},
+	{"wormlights", "resources", "发光浆果", "Glow Berries", worldgenFrequency, "default", true},
+	{"fern", "resources", "蕨类植物", "Ferns", worldgenFrequency, "default", true},
+	{"flower_cave", "resources", "荧光花", "Light Flowers", worldgenFrequency, "default", true},
+	{"banana", "resources", "洞穴香蕉", "Cave Bananas", worldgenFrequency, "default", true},
+	{"lichen", "resources", "苔藓", "Lichen", worldgenFrequency, "default", true},
+	{"mushtree", "resources", "蘑菇树", "Mushroom Trees", worldgenFrequency, "default", true},
+	{"rock", "resources", "巨石", "Boulders", worldgenFrequency, "default", true},
+	{"fissure", "resources", "噩梦裂隙", "Nightmare Fissures", worldgenFrequency, "default", true},
+	{"slurtles", "creatures", "蛞蝓龟窝", "Slurtle Mounds", worldgenFrequency, "default", true},
+	{"bats", "creatures", "蝙蝠", "Bats", worldgenFrequency, "default", true},
+	{"rocky", "creatures", "石虾", "Rock Lobsters", worldgenFrequency, "default", true},
+	{"monkey", "creatures", "猴子桶", "Splumonkey Pods", worldgenFrequency, "default", true},
+	{"cave_spiders", "creatures", "蛛网岩", "Spilagmites", worldgenFrequency, "default", true},
+	{"worms", "creatures", "洞穴蠕虫", "Depths Worms", worldgenFrequency, "default", true},
+	{"bunnymen", "creatures", "兔屋", "Rabbit Hutches", worldgenFrequency, "default", true},
+	{"earthquakes", "world", "地震", "Earthquakes", eventFrequency, "default", false},
+	{"wormattacks", "monsters", "洞穴蠕虫袭击", "Cave Worm Attacks", eventFrequency, "default", false},
+	{"atriumgate", "world", "远古大门冷却", "Ancient Gateway", speedValues, "default", false},
+}
+
+// GetLevelDataOptions 获取指定世界类型的所有已知配置项
+func GetLevelDataOptions(location, lang string) []LevelDataOption {
+	var defs []levelDataOptionDef
+	if location == LevelDataLocationCave {
+		defs = append(defs, caveOptionDefs...)
+	} else {
+		defs = append(defs, forestOptionDefs...)
+	}
+	defs = append(defs, survivorOptionDefs...)
+
+	options := make([]LevelDataOption, 0, len(defs))
+	for _, def := range defs {
+		label := def.en
+		if lang == "zh" {
+			label = def.zh
+		}
+		options = append(options, LevelDataOption{
+			Key:      def.key,
+			Group:    def.group,
+			Label:    label,
+			Values:   def.values,
+			Default:  def.def,
+			WorldGen: def.worldGen,
+		})
+	}
+
+	return options
+}
+
+// LevelDataOverrideError 世界配置校验失败的配置项
+// levelDataKeyRegex 配置项名称，强制修改时也必须符合
+var levelDataKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+type LevelDataOverrideError struct {
+	Key    string   `json:"key"`
+	Value  string   `json:"value"`
+	Reason string   `json:"reason"` // invalidKey unknownKey invalidValue
+	Values []string `json:"values"`
+}
+
+// ValidateLevelDataOverrides 校验配置项，已存在于文件中的未知配置项(通常来自模组或新版本)允许修改
+func ValidateLevelDataOverrides(data LevelData, overrides map[string]string) []LevelDataOverrideError {
+	options := make(map[string]LevelDataOption)
+	for _, option := range GetLevelDataOptions(data.Location(), "en") {
+		options[option.Key] = option
+	}
+	existing := data.Overrides()
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	errs := []LevelDataOverrideError{}
+	for _, key := range keys {
+		value := overrides[key]
+		option, known := options[key]
+		if !known {
+			if _, ok := existing[key]; !ok {
+				errs = append(errs, LevelDataOverrideError{Key: key, Value: value, Reason: "unknownKey"})
+			}
+			continue
+		}
+		if !slices.Contains(option.Values, value) {
+			errs = append(errs, LevelDataOverrideError{Key: key, Value: value, Reason: "invalidValue", Values: option.Values})
+		}
+	}
+
+	return errs
+}
+
+type LevelDataOverrideItem struct {
+	LevelDataOption
+	Value string `json:"value"`
+	Known bool   `json:"known"`
+}
+
+type LevelDataView struct {
+	Location  string                  `json:"location"`
+	Preset    string                  `json:"preset"`
+	Overrides []LevelDataOverrideItem `json:"overrides"`
+}
+
+// getLevelDataView 按配置项定义返回世界配置，文件中的未知配置项放在最后
+func (g *Game) getLevelDataView(worldID int) (*LevelDataView, error) {
+	world, err := g.getWorldModel(worldID)
+	if err != nil {
+		return &LevelDataView{}, err
+	}
+
+	data, err := ParseLevelData(world.LevelData)
+	if err != nil {
+		return &LevelDataView{}, err
+	}
+
+	view := &LevelDataView{
+		Location:  data.Location(),
+		Overrides: []LevelDataOverrideItem{},
+	}
+	for _, key := range []string{"id", "preset"} {
+		if preset, ok := data[key].(string); ok {
+			view.Preset = preset
+			break
+		}
+	}
+
+	overrides := data.Overrides()
+	for _, option := range GetLevelDataOptions(view.Location, g.lang) {
+		value, ok := overrides[option.Key]
+		if !ok {
+			value = option.Default
+		}
+		delete(overrides, option.Key)
+		view.Overrides = append(view.Overrides, LevelDataOverrideItem{LevelDataOption: option, Value: value, Known: true})
+	}
+
+	var unknownKeys []string
+	for key := range overrides {
+		unknownKeys = append(unknownKeys, key)
+	}
+	sort.Strings(unknownKeys)
+	for _, key := range unknownKeys {
+		view.Overrides = append(view.Overrides, LevelDataOverrideItem{
+			LevelDataOption: LevelDataOption{Key: key, Label: key, Group: "other"},
+			Value:           overrides[key],
+		})
+	}
+
+	return view, nil
+}
+
+// patchLevelData 修改世界配置中的overrides，校验失败时不做修改，force为true时跳过校验
+func (g *Game) patchLevelData(worldID int, overrides map[string]string, force bool) ([]LevelDataOverrideError, error) {
+	world, err := g.getWorldModel(worldID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ParseLevelData(world.LevelData)
+	if err != nil {
+		return nil, err
+	}
+
+	// 配置项名称会写入Lua代码，强制修改时同样校验
+	keyErrs := []LevelDataOverrideError{}
+	for key, value := range overrides {
+		if !levelDataKeyRegex.MatchString(key) {
+			keyErrs = append(keyErrs, LevelDataOverrideError{Key: key, Value: value, Reason: "invalidKey"})
+		}
+	}
+	if len(keyErrs) != 0 {
+		sort.Slice(keyErrs, func(i, j int) bool { return keyErrs[i].Key < keyErrs[j].Key })
+		return keyErrs, nil
+	}
+
+	if !force {
+		if errs := ValidateLevelDataOverrides(data, overrides); len(errs) != 0 {
+			return errs, nil
+		}
+	}
+
+	for key, value := range overrides {
+		data.SetOverride(key, value)
+	}
+	world.LevelData = data.ToLuaCode()
+
+	g.worldMutex.Lock()
+	defer g.worldMutex.Unlock()
+	for _, worldSave := range g.worldSaveData {
+		if worldSave.ID == worldID {
+			return nil, utils.TruncAndWriteFile(worldSave.levelDataOverridePath, world.LevelData)
+		}
+	}
+
+	return nil, nil
+}
+
+// getWorldModel 获取worlds中的世界，修改会保存到数据库
+func (g *Game) getWorldModel(worldID int) (*models.World, error) {
+	for i := range *g.worlds {
+		if (*g.worlds)[i].ID == worldID {
+			return &(*g.worlds)[i], nil
+		}
+	}
+
+	return &models.World{}, fmt.Errorf("世界%s不存在", strconv.Itoa(worldID))
+}
@@ -1,11 +1,16 @@
 package dst
 
 import (
+	"context"
 	"dst-management-platform-api/database/db"
 	"dst-management-platform-api/logger"
 	"dst-management-platform-api/utils"
 	"fmt"
 	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -68,7 +73,47 @@ func (g *Game) dsModsSetup() error {
 	return nil
 }
 
+// ModDownloadProgress 模组下载进度回调，total未知时为0
+type ModDownloadProgress func(bytes, total int64, percent float64)
+
+var (
+	steamcmdProgressRegex = regexp.MustCompile(`progress: ([\d.]+) \((\d+) / (\d+)\)`)
+	steamcmdSuccessRegex  = regexp.MustCompile(`Success\. Downloaded item \d+ .*\((\d+) bytes\)`)
+)
+
+// parseSteamcmdProgress 解析steamcmd输出中的下载进度
+func parseSteamcmdProgress(line string, progress ModDownloadProgress) {
+	if progress == nil {
+		return
+	}
+	if match := steamcmdProgressRegex.FindStringSubmatch(line); len(match) == 4 {
+		percent, _ := strconv.ParseFloat(match[1], 64)
+		bytes, _ := strconv.ParseInt(match[2], 10, 64)
+		total, _ := strconv.ParseInt(match[3], 10, 64)
+		progress(bytes, total, percent)
+		return
+	}
+	if match := steamcmdSuccessRegex.FindStringSubmatch(line); len(match) == 2 {
+		total, _ := strconv.ParseInt(match[1], 10, 64)
+		progress(total, total, 100)
+	}
+}
+
+// ModDownloader 通过下载队列下载模组并等待完成，由scheduler启动时注册，steamcmd和zip下载只在队列中执行
+var ModDownloader func(roomID, modID int, name, fileURL string) (int64, error)
+
+// downloadMod 将模组加入下载队列并等待下载完成
 func (g *Game) downloadMod(id int, fileURL string) (error, int64) {
+	if ModDownloader == nil {
+		return fmt.Errorf("模组下载队列未启动"), 0
+	}
+	size, err := ModDownloader(g.room.ID, id, "", fileURL)
+
+	return err, size
+}
+
+// downloadModContext 下载模组，ctx取消时终止下载，progress可为nil
+func (g *Game) downloadModContext(ctx context.Context, id int, fileURL string, progress ModDownloadProgress) (error, int64) {
 	atomic.AddInt32(&db.ModDownloadExecuting, 1)
 	defer atomic.AddInt32(&db.ModDownloadExecuting, -1)
 
@@ -84,13 +129,15 @@ func (g *Game) downloadMod(id int, fileURL string) (error, int64) {
 
 	if ugc {
 		// 1. ugc mod 统一下载到 dmp_files/ugc, 也就是dmp_files/ugc/{cluster}/steamapps/workshop{appworkshop_322330.acf  content  downloads}
-		// 2. 下载完成后，将下载的mod文件全部移动至dst/ugc_mods/{cluster}/{worlds}/ 删除-复制
+		// 2. 下载完成后，将下载的mod文件存入共享存储，并链接至dst/ugc_mods/{cluster}/{worlds}/
 		// 3. 读取游戏acf文件和dmp_files的acf文件，更新当前mod-id所对应的所有字段
 
 		// 1
 		downloadCmd := g.generateModDownloadCmd(id)
 		logger.Logger.Debug(downloadCmd)
-		err = utils.BashCMD(downloadCmd)
+		err = utils.BashCMDStream(ctx, downloadCmd, func(line string) {
+			parseSteamcmdProgress(line, progress)
+		})
 		if err != nil {
 			logger.Logger.Error("下载模组失败", "err", err)
 			return err, modSize
@@ -98,19 +145,12 @@ func (g *Game) downloadMod(id int, fileURL string) (error, int64) {
 		time.Sleep(500 * time.Millisecond)
 
 		// 2
-		err = g.removeGameOldMod(id)
-		if err != nil {
-			logger.Logger.Error("移动模组失败", "err", err)
-			return err, modSize
-		}
-		copyCmd := g.generateModCopyCmd(id)
-		logger.Logger.Debug(copyCmd)
-		err = utils.BashCMD(copyCmd)
+		dmpPath := fmt.Sprintf("%s/mods/ugc/%s/steamapps/workshop/content/322330/%d", utils.DmpFiles, g.clusterName, id)
+		storePath, err := g.storeAndLinkMod(id, dmpPath)
 		if err != nil {
 			logger.Logger.Error("移动模组失败", "err", err)
 			return err, modSize
 		}
-		time.Sleep(500 * time.Millisecond)
 
 		// 3
 		gameAcfPath := fmt.Sprintf("dst/ugc_mods/%s/%s/appworkshop_322330.acf", g.clusterName, g.worldSaveData[0].WorldName)
@@ -135,8 +175,8 @@ func (g *Game) downloadMod(id int, fileURL string) (error, int64) {
 		}
 		time.Sleep(500 * time.Millisecond)
 
-		modSize, err = utils.GetDirSize(fmt.Sprintf("dst/ugc_mods/%s/%s/content/322330/%d", g.clusterName, g.worldSaveData[0].WorldName, id))
-		logger.Logger.DebugF("模组路径为%s", fmt.Sprintf("dst/ugc_mods/%s/%s/content/322330/%d", g.clusterName, g.worldSaveData[0].WorldName, id))
+		modSize, err = utils.GetDirSize(storePath)
+		logger.Logger.DebugF("模组路径为%s", storePath)
 		logger.Logger.DebugF("模组大小为%d", modSize)
 		if err != nil {
 			logger.Logger.Error("获取模组大小失败", "err", err)
@@ -146,7 +186,7 @@ func (g *Game) downloadMod(id int, fileURL string) (error, int64) {
 	} else {
 		// 1. 下载zip文件并保存
 		// 2. 解压zip文件至dst/mods/workshop-id
-		err, modSize = downloadNotUGCMod(fileURL, id)
+		err, modSize = downloadNotUGCMod(ctx, fileURL, id, progress)
 		if err != nil {
 			logger.Logger.Error("下载mod失败", "err", err)
 			return err, modSize
@@ -160,38 +200,6 @@ func (g *Game) generateModDownloadCmd(id int) string {
 	return fmt.Sprintf("steamcmd/steamcmd.sh +force_install_dir %s/%s/mods/ugc/%s +login anonymous +workshop_download_item 322330 %d +quit", db.CurrentDir, utils.DmpFiles, g.clusterName, id)
 }
 
-func (g *Game) removeGameOldMod(id int) error {
-	for _, world := range g.worldSaveData {
-		path := fmt.Sprintf("dst/ugc_mods/%s/%s/content/322330/%d", g.clusterName, world.WorldName, id)
-		err := utils.RemoveDir(path)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (g *Game) generateModCopyCmd(id int) string {
-	if len(g.worldSaveData) == 0 {
-		return ""
-	}
-
-	dmpPath := fmt.Sprintf("%s/mods/ugc/%s/steamapps/workshop/content/322330/%d", utils.DmpFiles, g.clusterName, id)
-
-	var cmds []string
-
-	// 生成 复制 命令
-	for _, world := range g.worldSaveData {
-		gamePath := fmt.Sprintf("dst/ugc_mods/%s/%s/content/322330/%d", g.clusterName, world.WorldName, id)
-		cmd := fmt.Sprintf("mkdir -p dst/ugc_mods/%s/%s/content/322330", g.clusterName, world.WorldName)
-		cmds = append(cmds, cmd)
-		cmd = fmt.Sprintf("cp -r %s %s", dmpPath, gamePath)
-		cmds = append(cmds, cmd)
-	}
-
-	return strings.Join(cmds, " && ")
-}
-
 func (g *Game) processAcf(id int) error {
 	g.acfMutex.Lock()
 	defer g.acfMutex.Unlock()
@@ -278,12 +286,16 @@ func (g *Game) processAcf(id int) error {
 }
 
 type DownloadedMod struct {
-	ID         int    `json:"id"`
-	Name       string `json:"name"`
-	LocalSize  string `json:"localSize"`
-	ServerSize string `json:"serverSize"`
-	FileURL    string `json:"file_url"`
-	PreviewURL string `json:"preview_url"`
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	LocalSize         string `json:"localSize"`
+	ServerSize        string `json:"serverSize"`
+	FileURL           string `json:"file_url"`
+	PreviewURL        string `json:"preview_url"`
+	Version           string `json:"version"`
+	LocalTimeUpdated  int64  `json:"localTimeUpdated"`
+	ServerTimeUpdated int64  `json:"serverTimeUpdated"`
+	Outdated          bool   `json:"outdated"`
 }
 
 func (g *Game) getDownloadedMods() *[]DownloadedMod {
@@ -298,9 +310,16 @@ func (g *Game) getDownloadedMods() *[]DownloadedMod {
 				idStr := parts[len(parts)-1]
 				id, err := strconv.Atoi(idStr)
 				if err == nil {
+					// 非ugc模组没有acf文件，使用目录的修改时间作为安装时间
+					var localTimeUpdated int64
+					if info, err := os.Stat(fmt.Sprintf("dst/mods/%s", dir)); err == nil {
+						localTimeUpdated = info.ModTime().Unix()
+					}
 					downloadedMods = append(downloadedMods, DownloadedMod{
-						ID:        id,
-						LocalSize: "0",
+						ID:               id,
+						LocalSize:        "0",
+						Version:          getModVersion(fmt.Sprintf("dst/mods/%s/modinfo.lua", dir), id),
+						LocalTimeUpdated: localTimeUpdated,
 					})
 				}
 			}
@@ -327,9 +346,12 @@ func (g *Game) getDownloadedMods() *[]DownloadedMod {
 			if err != nil {
 				id = 0
 			}
+			localTimeUpdated, _ := strconv.ParseInt(mod.TimeUpdated, 10, 64)
 			downloadedMods = append(downloadedMods, DownloadedMod{
-				ID:        id,
-				LocalSize: mod.Size,
+				ID:               id,
+				LocalSize:        mod.Size,
+				Version:          getModVersion(fmt.Sprintf("%s/%s/content/322330/%d/modinfo.lua", g.ugcPath, g.worldSaveData[0].WorldName, id), id),
+				LocalTimeUpdated: localTimeUpdated,
 			})
 		}
 	}
@@ -410,10 +432,35 @@ func (g *Game) getModConfigureOptionsValues(worldID, modID int, ugc bool) (*ModO
 }
 
 func (g *Game) modEnable(worldID, modID int, ugc bool) error {
+	if err := g.eventBackup(BackupEventMod); err != nil {
+		logger.Logger.Error("模组启用前备份失败", "err", err)
+	}
+
+	if err := g.addModConfig(worldID, modID, ugc); err != nil {
+		return err
+	}
+
+	// 自动下载并启用依赖模组
+	if modID != 0 {
+		g.enableModDependencies(worldID, modID, map[int]bool{modID: true})
+	}
+
+	// 统一保存文件
+	return g.saveMods()
+}
+
+// addModConfig 使用默认配置将模组写入modoverrides，不保存文件
+func (g *Game) addModConfig(worldID, modID int, ugc bool) error {
+	return g.addModConfigToWorlds(worldID, modID, ugc, nil, nil)
+}
+
+// addModConfigToWorlds 使用默认配置将模组写入指定世界的modoverrides，targetWorldIDs为空则写入所有世界，overrides覆盖默认配置
+func (g *Game) addModConfigToWorlds(worldID, modID int, ugc bool, targetWorldIDs []int, overrides map[string]any) error {
 	var (
 		err     error
 		options *[]ConfigurationOption
 	)
+
 	// 区分是否为禁本地配置
 	if modID == 0 {
 		options = &[]ConfigurationOption{}
@@ -425,15 +472,31 @@ func (g *Game) modEnable(worldID, modID int, ugc bool) error {
 		}
 	}
 
+	// 区分是否为禁本地配置
+	modKey := fmt.Sprintf("workshop-%d", modID)
+	if modID == 0 {
+		modKey = "client_mods_disabled"
+	}
+
+	return g.addModKeyConfig(modKey, *options, targetWorldIDs, overrides)
+}
+
+// addModKeyConfig 使用默认配置将指定key的模组写入modoverrides，本地模组的key为目录名
+func (g *Game) addModKeyConfig(modKey string, options []ConfigurationOption, targetWorldIDs []int, overrides map[string]any) error {
+	var err error
+
 	newModConfig := &ModORConfig{
 		ConfigurationOptions: make(map[string]any),
 		Enabled:              true,
 	}
-	for _, option := range *options {
+	for _, option := range options {
 		key := option.Name
 		value := option.Default
 		newModConfig.ConfigurationOptions[key] = value
 	}
+	for key, value := range overrides {
+		newModConfig.ConfigurationOptions[key] = value
+	}
 
 	modORParser := NewModORParser()
 	defer modORParser.close()
@@ -449,18 +512,17 @@ func (g *Game) modEnable(worldID, modID int, ugc bool) error {
 				return err
 			}
 		}
-		// 区分是否为禁本地配置
-		if modID == 0 {
-			mods.AddModConfig(fmt.Sprintf("client_mods_disabled"), newModConfig)
-		} else {
-			mods.AddModConfig(fmt.Sprintf("workshop-%d", modID), newModConfig)
-		}
+		mods.AddModConfig(modKey, newModConfig)
 		newModORContent := mods.ToLuaCode()
 		g.room.ModData = newModORContent
 	} else {
 		// 为保留每个世界的独立模组配置，需要分开处理，增加指定的mod，并修改db，最后返回
 		worlds := *g.worlds
-		for i, world := range g.worldSaveData {
+		for i, world := range worlds {
+			if len(targetWorldIDs) != 0 && !slices.Contains(targetWorldIDs, world.ID) {
+				continue
+			}
+			// 连续启用多个模组时worldSaveData中的数据不会更新，需要从worlds中读取
 			modORContent = world.ModData
 			mods := make(ModORCollection)
 			if modORContent != "" {
@@ -471,20 +533,14 @@ func (g *Game) modEnable(worldID, modID int, ugc bool) error {
 				}
 			}
 
-			// 区分是否为禁本地配置
-			if modID == 0 {
-				mods.AddModConfig(fmt.Sprintf("client_mods_disabled"), newModConfig)
-			} else {
-				mods.AddModConfig(fmt.Sprintf("workshop-%d", modID), newModConfig)
-			}
+			mods.AddModConfig(modKey, newModConfig)
 			newModORContent := mods.ToLuaCode()
 
 			worlds[i].ModData = newModORContent
 		}
 	}
 
-	// 统一保存文件
-	return g.saveMods()
+	return nil
 }
 
 func (g *Game) saveMods() error {
@@ -505,51 +561,196 @@ func (g *Game) saveMods() error {
 	return nil
 }
 
-func (g *Game) modConfigureOptionsValuesChange(worldID, modID int, modConfig *ModORConfig) error {
+// ModOptionError 模组配置校验失败的配置项
+type ModOptionError struct {
+	Key     string `json:"key"`
+	Value   any    `json:"value"`
+	Reason  string `json:"reason"` // unknownKey invalidValue
+	Options []any  `json:"options"`
+}
+
+// ValidateModConfig 校验配置值是否为modinfo.lua中的可选项，existing中已有的未知配置项允许保留
+func ValidateModConfig(options []ConfigurationOption, config, existing map[string]any) []ModOptionError {
+	optionMap := make(map[string]ConfigurationOption)
+	for _, option := range options {
+		optionMap[option.Name] = option
+	}
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	errs := []ModOptionError{}
+	for _, key := range keys {
+		value := config[key]
+		option, ok := optionMap[key]
+		if !ok {
+			if _, exist := existing[key]; !exist {
+				errs = append(errs, ModOptionError{Key: key, Value: value, Reason: "unknownKey"})
+			}
+			continue
+		}
+		// 标题等没有可选项的配置不校验
+		if len(option.Options) == 0 {
+			continue
+		}
+		var (
+			valid   bool
+			allowed []any
+		)
+		for _, opt := range option.Options {
+			allowed = append(allowed, opt.Data)
+			if reflect.DeepEqual(opt.Data, value) {
+				valid = true
+			}
+		}
+		if !valid {
+			errs = append(errs, ModOptionError{Key: key, Value: value, Reason: "invalidValue", Options: allowed})
+		}
+	}
+
+	return errs
+}
+
+// getModConfigOptions 获取已下载模组的配置项，自动判断是否为ugc模组
+func (g *Game) getModConfigOptions(worldID, modID int) (*[]ConfigurationOption, error) {
+	_, ugc, err := g.getModPath(modID)
+	if err != nil {
+		return &[]ConfigurationOption{}, err
+	}
+
+	return g.getModConfigureOptions(worldID, modID, ugc)
+}
+
+// setModOverrides 修改房间或世界的modoverrides.lua内容，需要调用saveMods写入文件
+func (g *Game) setModOverrides(worldID int, content string) {
+	if g.room.ModInOne {
+		g.room.ModData = content
+		return
+	}
+
+	for i := range *g.worlds {
+		if (*g.worlds)[i].ID == worldID {
+			(*g.worlds)[i].ModData = content
+		}
+	}
+}
+
+func (g *Game) modConfigureOptionsValuesChange(worldID, modID int, modConfig *ModORConfig, force bool) ([]ModOptionError, error) {
 	g.modMutex.Lock()
 	defer g.modMutex.Unlock()
 
 	modORParser := NewModORParser()
 	defer modORParser.close()
 
-	var modORContent string
-	if g.room.ModInOne {
-		modORContent = g.room.ModData
-	} else {
-		world, err := g.getWorldByID(worldID)
-		if err != nil {
-			logger.Logger.Debug("这里出问题?", "err", err)
-			return err
-		}
-		modORContent = world.ModData
+	modORContent, err := g.getModOverrides(worldID)
+	if err != nil {
+		logger.Logger.Debug("这里出问题?", "err", err)
+		return nil, err
 	}
 
 	mods, err := modORParser.Parse(modORContent, g.lang)
 	if err != nil {
 		logger.Logger.Debug("这里出问题?", "err", err)
-		return err
+		return nil, err
 	}
 
 	modKey := fmt.Sprintf("workshop-%d", modID)
 
+	// 错误的配置值是模组加载时崩溃的常见原因
+	if !force && modID != 0 {
+		options, err := g.getModConfigOptions(worldID, modID)
+		if err != nil {
+			return nil, err
+		}
+		existing := make(map[string]any)
+		if mod, ok := mods[modKey]; ok && mod.ConfigurationOptions != nil {
+			existing = mod.ConfigurationOptions
+		}
+		if errs := ValidateModConfig(*options, modConfig.ConfigurationOptions, existing); len(errs) != 0 {
+			return errs, nil
+		}
+	}
+
 	mods[modKey] = modConfig
 
-	newModORContent := mods.ToLuaCode()
+	g.setModOverrides(worldID, mods.ToLuaCode())
 
-	if g.room.ModInOne {
-		g.room.ModData = newModORContent
-	} else {
-		for i := range g.worldSaveData {
-			worlds := *g.worlds
-			if worlds[i].ID == worldID {
-				worlds[i].ModData = newModORContent
-			}
-		}
+	return nil, g.saveMods()
+}
+
+// getModDefaultConfig 获取模组的默认配置
+func (g *Game) getModDefaultConfig(worldID, modID int) (map[string]any, error) {
+	options, err := g.getModConfigOptions(worldID, modID)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := make(map[string]any)
+	for _, option := range *options {
+		defaults[option.Name] = option.Default
+	}
+
+	return defaults, nil
+}
+
+// modConfigReset 将模组配置重置为默认值，保留启用状态
+func (g *Game) modConfigReset(worldID, modID int) error {
+	g.modMutex.Lock()
+	defer g.modMutex.Unlock()
+
+	defaults, err := g.getModDefaultConfig(worldID, modID)
+	if err != nil {
+		return err
+	}
+
+	modORParser := NewModORParser()
+	defer modORParser.close()
+
+	modORContent, err := g.getModOverrides(worldID)
+	if err != nil {
+		return err
 	}
 
+	mods, err := modORParser.Parse(modORContent, g.lang)
+	if err != nil {
+		return err
+	}
+
+	modKey := fmt.Sprintf("workshop-%d", modID)
+	mod, ok := mods[modKey]
+	if !ok {
+		return fmt.Errorf("在modoverrides.lua文件中没有找到该mod的配置")
+	}
+	mod.ConfigurationOptions = defaults
+
+	g.setModOverrides(worldID, mods.ToLuaCode())
+
 	return g.saveMods()
 }
 
+// modConfigDiff 对比模组当前配置和默认配置，Old为默认值，New为当前值
+func (g *Game) modConfigDiff(worldID, modID int) ([]ModOptionDiff, error) {
+	defaults, err := g.getModDefaultConfig(worldID, modID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := g.getModConfigureOptionsValues(worldID, modID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := diffModOptions(defaults, current.ConfigurationOptions)
+	if diffs == nil {
+		diffs = []ModOptionDiff{}
+	}
+
+	return diffs, nil
+}
+
 func (g *Game) getEnabledMods(worldID int) ([]DownloadedMod, error) {
 	modORParser := NewModORParser()
 	defer modORParser.close()
@@ -578,6 +779,10 @@ func (g *Game) getEnabledMods(worldID int) ([]DownloadedMod, error) {
 
 	var modsID []DownloadedMod
 	for k := range mods {
+		// 本地模组通过本地模组接口管理
+		if isLocalModKey(k) {
+			continue
+		}
 		modIDSlice := strings.Split(k, "-")
 		var modID int
 		if len(modIDSlice) < 2 {
@@ -598,6 +803,20 @@ func (g *Game) getEnabledMods(worldID int) ([]DownloadedMod, error) {
 }
 
 func (g *Game) modDisable(modID int) error {
+	// 区分是否为禁本地配置
+	if modID == 0 {
+		return g.modDisableKey("client_mods_disabled")
+	}
+
+	return g.modDisableKey(fmt.Sprintf("workshop-%d", modID))
+}
+
+// modDisableKey 从modoverrides中删除指定key的模组
+func (g *Game) modDisableKey(modKey string) error {
+	if err := g.eventBackup(BackupEventMod); err != nil {
+		logger.Logger.Error("模组禁用前备份失败", "err", err)
+	}
+
 	modORParser := NewModORParser()
 	defer modORParser.close()
 
@@ -609,12 +828,7 @@ func (g *Game) modDisable(modID int) error {
 			logger.Logger.Debug("这里出问题?", "err", err)
 			return err
 		}
-		// 区分是否为禁本地配置
-		if modID == 0 {
-			delete(mods, fmt.Sprintf("client_mods_disabled"))
-		} else {
-			delete(mods, fmt.Sprintf("workshop-%d", modID))
-		}
+		delete(mods, modKey)
 
 		newModORContent := mods.ToLuaCode()
 
@@ -630,12 +844,7 @@ func (g *Game) modDisable(modID int) error {
 				return err
 			}
 
-			// 区分是否为禁本地配置
-			if modID == 0 {
-				delete(mods, fmt.Sprintf("client_mods_disabled"))
-			} else {
-				delete(mods, fmt.Sprintf("workshop-%d", modID))
-			}
+			delete(mods, modKey)
 
 			newModORContent := mods.ToLuaCode()
 
@@ -698,6 +907,11 @@ func (g *Game) deleteMod(modID int, fileURL string) error {
 				return err
 			}
 		}
+
+		// 共享存储中的模组没有其他房间引用时才删除
+		if _, err := PruneModStore(); err != nil {
+			logger.Logger.Error("清理共享模组失败", "err", err)
+		}
 	} else {
 		err := utils.RemoveDir(fmt.Sprintf("dst/mods/workshop-%d", modID))
 		if err != nil {
@@ -708,3 +922,342 @@ func (g *Game) deleteMod(modID int, fileURL string) error {
 
 	return nil
 }
+
+// getModVersion 读取modinfo.lua中的版本号
+func getModVersion(modinfoLuaPath string, modID int) string {
+	parser, err := NewModInfoParser(modinfoLuaPath, modID)
+	if err != nil {
+		return ""
+	}
+	if err = parser.Parse("zh"); err != nil {
+		return ""
+	}
+
+	return parser.Version
+}
+
+// checkModUpdates 对比本地模组与创意工坊的更新时间，返回所有已下载模组
+func (g *Game) checkModUpdates() ([]DownloadedMod, error) {
+	downloadedMods := *g.getDownloadedMods()
+
+	var ids []int
+	for _, mod := range downloadedMods {
+		if mod.ID != 0 {
+			ids = append(ids, mod.ID)
+		}
+	}
+
+	items, err := GetWorkshopItems(ids, g.lang)
+	if err != nil {
+		return downloadedMods, err
+	}
+
+	for index, mod := range downloadedMods {
+		item, ok := items[mod.ID]
+		if !ok {
+			continue
+		}
+		downloadedMods[index].Name = item.Title
+		downloadedMods[index].FileURL = item.FileURL
+		downloadedMods[index].PreviewURL = item.PreviewURL
+		downloadedMods[index].ServerSize = item.FileSize
+		downloadedMods[index].ServerTimeUpdated = item.TimeUpdated
+		downloadedMods[index].Outdated = mod.LocalTimeUpdated != 0 && item.TimeUpdated > mod.LocalTimeUpdated
+	}
+
+	return downloadedMods, nil
+}
+
+// getModPath 获取已下载模组的目录，优先查找ugc模组
+func (g *Game) getModPath(modID int) (string, bool, error) {
+	ugcModPath := fmt.Sprintf("%s/%s/content/322330/%d", g.ugcPath, g.worldSaveData[0].WorldName, modID)
+	if utils.FileDirectoryExists(ugcModPath) {
+		return ugcModPath, true, nil
+	}
+
+	modPath := fmt.Sprintf("dst/mods/workshop-%d", modID)
+	if utils.FileDirectoryExists(modPath) {
+		return modPath, false, nil
+	}
+
+	return "", false, fmt.Errorf("模组%d未下载", modID)
+}
+
+// getModInfo 解析已下载模组的modinfo.lua
+func (g *Game) getModInfo(modID int) (*ModInfoParser, error) {
+	modPath, _, err := g.getModPath(modID)
+	if err != nil {
+		return &ModInfoParser{}, err
+	}
+
+	parser, err := NewModInfoParser(fmt.Sprintf("%s/modinfo.lua", modPath), modID)
+	if err != nil {
+		return parser, err
+	}
+
+	err = parser.Parse(g.lang)
+
+	return parser, err
+}
+
+// getModPrefabs 获取模组scripts/prefabs下的预制物文件
+func getModPrefabs(modPath string) []string {
+	files, err := utils.GetFiles(fmt.Sprintf("%s/scripts/prefabs", modPath))
+	if err != nil {
+		return []string{}
+	}
+
+	var prefabs []string
+	for _, file := range files {
+		if prefab, found := strings.CutSuffix(file, ".lua"); found {
+			prefabs = append(prefabs, prefab)
+		}
+	}
+
+	return prefabs
+}
+
+// getEnabledModIDs 获取已启用的模组ID，不包含禁本地配置
+func (g *Game) getEnabledModIDs(worldID int) (map[int]bool, error) {
+	enabledMods, err := g.getEnabledMods(worldID)
+	if err != nil {
+		return map[int]bool{}, err
+	}
+
+	enabled := make(map[int]bool)
+	for _, mod := range enabledMods {
+		if mod.ID != 0 {
+			enabled[mod.ID] = true
+		}
+	}
+
+	return enabled, nil
+}
+
+// enableModDependencies 递归下载并启用模组依赖，失败只记录日志，由模组校验提示给用户
+func (g *Game) enableModDependencies(worldID, modID int, visited map[int]bool) {
+	info, err := g.getModInfo(modID)
+	if err != nil {
+		logger.Logger.Warn("读取模组信息失败，跳过依赖检查", "err", err, "mod", modID)
+		return
+	}
+
+	for _, dependency := range info.Dependencies {
+		if len(dependency.IDs) == 0 {
+			continue
+		}
+
+		enabled, err := g.getEnabledModIDs(worldID)
+		if err != nil {
+			logger.Logger.Error("获取启用模组失败", "err", err)
+			return
+		}
+		if slices.ContainsFunc(dependency.IDs, func(id int) bool { return enabled[id] }) {
+			continue
+		}
+
+		depID := dependency.IDs[0]
+		if visited[depID] {
+			continue
+		}
+		visited[depID] = true
+
+		_, ugc, err := g.getModPath(depID)
+		if err != nil {
+			items, err := GetWorkshopItems([]int{depID}, g.lang)
+			if err != nil {
+				logger.Logger.Error("获取依赖模组信息失败", "err", err, "mod", depID)
+				continue
+			}
+			err, _ = g.downloadMod(depID, items[depID].FileURL)
+			if err != nil {
+				logger.Logger.Error("下载依赖模组失败", "err", err, "mod", depID)
+				continue
+			}
+			ugc = items[depID].FileURL == ""
+		}
+
+		err = g.addModConfig(worldID, depID, ugc)
+		if err != nil {
+			logger.Logger.Error("启用依赖模组失败", "err", err, "mod", depID)
+			continue
+		}
+		logger.Logger.Info("已自动启用依赖模组", "mod", modID, "dependency", depID)
+
+		g.enableModDependencies(worldID, depID, visited)
+	}
+}
+
+const (
+	ModIssueNotDownloaded     = "notDownloaded"
+	ModIssueParseFail         = "parseFail"
+	ModIssueMissingDependency = "missingDependency"
+	ModIssueNotDSTCompatible  = "notDstCompatible"
+	ModIssueAPIVersion        = "apiVersion"
+	ModIssueClientOnly        = "clientOnly"
+	ModIssueFlagConflict      = "flagConflict"
+	ModIssueDuplicate         = "duplicate"
+	ModIssuePrefabConflict    = "prefabConflict"
+)
+
+// modAPIVersionDST 饥荒联机版模组的api_version
+const modAPIVersionDST = 10
+
+type ModIssue struct {
+	Type    string `json:"type"`
+	ModID   int    `json:"modID"`
+	Related []int  `json:"related"`
+	Detail  string `json:"detail"`
+}
+
+type ModCheckInfo struct {
+	ID                   int             `json:"id"`
+	Name                 string          `json:"name"`
+	Version              string          `json:"version"`
+	Priority             float64         `json:"priority"`
+	APIVersion           int             `json:"apiVersion"`
+	DSTCompatible        bool            `json:"dstCompatible"`
+	ServerOnlyMod        bool            `json:"serverOnlyMod"`
+	AllClientsRequireMod bool            `json:"allClientsRequireMod"`
+	ClientOnlyMod        bool            `json:"clientOnlyMod"`
+	Dependencies         []ModDependency `json:"dependencies"`
+	Prefabs              []string        `json:"prefabs"`
+}
+
+type ModValidation struct {
+	Valid  bool           `json:"valid"`
+	Mods   []ModCheckInfo `json:"mods"`
+	Issues []ModIssue     `json:"issues"`
+}
+
+// validateMods 校验已启用的模组，检查依赖缺失、兼容性标记和重复模组
+func (g *Game) validateMods(worldID int) (*ModValidation, error) {
+	enabled, err := g.getEnabledModIDs(worldID)
+	if err != nil {
+		return &ModValidation{}, err
+	}
+
+	var ids []int
+	for id := range enabled {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	validation := &ModValidation{
+		Mods:   []ModCheckInfo{},
+		Issues: []ModIssue{},
+	}
+	addIssue := func(issueType string, modID int, related []int, detail string) {
+		validation.Issues = append(validation.Issues, ModIssue{
+			Type:    issueType,
+			ModID:   modID,
+			Related: related,
+			Detail:  detail,
+		})
+	}
+
+	modNames := make(map[string][]int)
+	prefabOwners := make(map[string][]int)
+
+	for _, id := range ids {
+		modPath, _, err := g.getModPath(id)
+		if err != nil {
+			addIssue(ModIssueNotDownloaded, id, nil, err.Error())
+			continue
+		}
+
+		info, err := g.getModInfo(id)
+		if err != nil {
+			addIssue(ModIssueParseFail, id, nil, err.Error())
+			continue
+		}
+
+		checkInfo := ModCheckInfo{
+			ID:                   id,
+			Name:                 info.Name,
+			Version:              info.Version,
+			Priority:             info.Priority,
+			APIVersion:           info.APIVersion,
+			DSTCompatible:        info.DSTCompatible,
+			ServerOnlyMod:        info.ServerOnlyMod,
+			AllClientsRequireMod: info.AllClientsRequireMod,
+			ClientOnlyMod:        info.ClientOnlyMod,
+			Dependencies:         info.Dependencies,
+			Prefabs:              getModPrefabs(modPath),
+		}
+		validation.Mods = append(validation.Mods, checkInfo)
+
+		for _, dependency := range info.Dependencies {
+			if len(dependency.IDs) == 0 {
+				continue
+			}
+			if !slices.ContainsFunc(dependency.IDs, func(depID int) bool { return enabled[depID] }) {
+				addIssue(ModIssueMissingDependency, id, dependency.IDs, strings.Join(dependency.Names, ","))
+			}
+		}
+
+		if !info.DSTCompatible {
+			addIssue(ModIssueNotDSTCompatible, id, nil, "dst_compatible")
+		}
+		if info.APIVersion != modAPIVersionDST {
+			addIssue(ModIssueAPIVersion, id, nil, strconv.Itoa(info.APIVersion))
+		}
+		if info.ClientOnlyMod {
+			addIssue(ModIssueClientOnly, id, nil, "client_only_mod")
+		}
+		if info.ServerOnlyMod && info.AllClientsRequireMod {
+			addIssue(ModIssueFlagConflict, id, nil, "server_only_mod, all_clients_require_mod")
+		}
+
+		if info.Name != "" {
+			modNames[info.Name] = append(modNames[info.Name], id)
+		}
+		for _, prefab := range checkInfo.Prefabs {
+			prefabOwners[prefab] = append(prefabOwners[prefab], id)
+		}
+	}
+
+	// 遍历map的顺序不固定，按键排序后再生成问题，保证每次返回的顺序一致
+	// 同名模组，通常是同一模组的不同版本
+	names := make([]string, 0, len(modNames))
+	for name := range modNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if modIDs := modNames[name]; len(modIDs) > 1 {
+			addIssue(ModIssueDuplicate, modIDs[0], modIDs[1:], name)
+		}
+	}
+
+	// 多个模组提供同名预制物，后加载的会覆盖先加载的
+	prefabNames := make([]string, 0, len(prefabOwners))
+	for prefab := range prefabOwners {
+		prefabNames = append(prefabNames, prefab)
+	}
+	sort.Strings(prefabNames)
+	conflicts := make(map[string][]string)
+	var conflictKeys []string
+	for _, prefab := range prefabNames {
+		modIDs := prefabOwners[prefab]
+		if len(modIDs) > 1 {
+			key := fmt.Sprint(modIDs)
+			if _, ok := conflicts[key]; !ok {
+				conflictKeys = append(conflictKeys, key)
+			}
+			conflicts[key] = append(conflicts[key], prefab)
+		}
+	}
+	for _, key := range conflictKeys {
+		prefabs := conflicts[key]
+		modIDs := prefabOwners[prefabs[0]]
+		addIssue(ModIssuePrefabConflict, modIDs[0], modIDs[1:], strings.Join(prefabs, ","))
+	}
+
+	sort.SliceStable(validation.Issues, func(i, j int) bool {
+		return validation.Issues[i].ModID < validation.Issues[j].ModID
+	})
+	validation.Valid = len(validation.Issues) == 0
+
+	return validation, nil
+}
@@ -0,0 +1,84 @@
+package dst
+
+import (
+	"dst-management-platform-api/utils"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ============== //
+// 玩家客户端模组清单
+// ============== //
+
+const workshopItemURL = "https://steamcommunity.com/sharedfiles/filedetails/?id=%d"
+
+// ClientMod 玩家加入房间前需要订阅的模组
+type ClientMod struct {
+	DownloadedMod
+	WorkshopURL string   `json:"workshopURL"`
+	Required    bool     `json:"required"` // all_clients_require_mod，玩家必须订阅
+	ClientOnly  bool     `json:"clientOnly"`
+	Downloaded  bool     `json:"downloaded"` // 未下载的模组无法读取modinfo.lua，按需要订阅处理
+	Worlds      []string `json:"worlds"`
+}
+
+// getClientMods 获取房间所有世界已启用的模组，并读取modinfo.lua判断玩家是否需要订阅
+func (g *Game) getClientMods() ([]ClientMod, error) {
+	modORParser := NewModORParser()
+	defer modORParser.close()
+
+	modWorlds := make(map[int][]string)
+	for _, world := range g.worldSaveData {
+		modORContent, err := g.getModOverrides(world.ID)
+		if err != nil {
+			return []ClientMod{}, err
+		}
+		if modORContent == "" {
+			continue
+		}
+		mods, err := modORParser.Parse(modORContent, g.lang)
+		if err != nil {
+			return []ClientMod{}, err
+		}
+		for key, mod := range mods {
+			modID, ok := ParseWorkshopKey(key)
+			if !ok || !mod.Enabled {
+				continue
+			}
+			modWorlds[modID] = append(modWorlds[modID], world.WorldName)
+		}
+	}
+
+	clientMods := make([]ClientMod, 0, len(modWorlds))
+	for modID, worlds := range modWorlds {
+		clientMod := ClientMod{
+			DownloadedMod: DownloadedMod{ID: modID, LocalSize: "0"},
+			WorkshopURL:   fmt.Sprintf(workshopItemURL, modID),
+			Required:      true,
+			Worlds:        worlds,
+		}
+
+		info, err := g.getModInfo(modID)
+		if err == nil {
+			clientMod.Downloaded = true
+			clientMod.Name = info.Name
+			clientMod.Version = info.Version
+			clientMod.Required = info.AllClientsRequireMod && !info.ServerOnlyMod
+			clientMod.ClientOnly = info.ClientOnlyMod
+			if modPath, _, err := g.getModPath(modID); err == nil {
+				if size, err := utils.GetDirSize(modPath); err == nil {
+					clientMod.LocalSize = strconv.FormatInt(size, 10)
+				}
+			}
+		}
+
+		clientMods = append(clientMods, clientMod)
+	}
+
+	sort.Slice(clientMods, func(i, j int) bool {
+		return clientMods[i].ID < clientMods[j].ID
+	})
+
+	return clientMods, nil
+}
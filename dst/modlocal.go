@@ -0,0 +1,320 @@
+package dst
+
+import (
+	"dst-management-platform-api/database/db"
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ============== //
+// 本地(私有)模组
+// 安装在dst/mods/<name>，modoverrides中的key为目录名，历史版本的zip保存在dmp_files/mods/local/<name>
+// ============== //
+
+var (
+	localModMutex   sync.Mutex
+	localModNameRe  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+	localModVerRe   = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+	localModMaxLua  = int64(1 << 20)
+	localModTempDir = ".tmp"
+	// localModMaxExtractedSize 解压后的总大小上限
+	localModMaxExtractedSize = int64(1 << 30)
+)
+
+// LocalModMaxUploadSize 上传的本地模组zip大小上限
+const LocalModMaxUploadSize = int64(256 << 20)
+
+type LocalModInfo struct {
+	Name    string `json:"name"`
+	Title   string `json:"title"`
+	Version string `json:"version"`
+	Size    int64  `json:"size"`
+}
+
+// ValidLocalModName 本地模组目录名只允许字母数字和_.-，且不能和创意工坊模组冲突
+func ValidLocalModName(name string) bool {
+	return localModNameRe.MatchString(name) && !strings.HasPrefix(name, "workshop-")
+}
+
+func localModPath(name string) string {
+	return fmt.Sprintf("%s/dst/mods/%s", db.CurrentDir, name)
+}
+
+func localModArchiveDir(name string) string {
+	return fmt.Sprintf("%s/%s/mods/local/%s", db.CurrentDir, utils.DmpFiles, name)
+}
+
+// LocalModArchivePath 本地模组指定版本的zip路径，以数据库记录的ID命名，避免不同版本号转换后冲突
+func LocalModArchivePath(name string, id int) string {
+	return fmt.Sprintf("%s/%d.zip", localModArchiveDir(name), id)
+}
+
+// legacyLocalModArchivePath 旧版本以版本号命名的zip路径
+func legacyLocalModArchivePath(name, version string) string {
+	return fmt.Sprintf("%s/%s.zip", localModArchiveDir(name), localModVerRe.ReplaceAllString(version, "_"))
+}
+
+// findModInfoInZip 查找zip中的modinfo.lua，支持放在根目录或唯一的一级目录下，返回模组根目录
+func findModInfoInZip(zipPath string) (string, error) {
+	entries, err := utils.ListZip(zipPath)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		roots []string
+		size  int64
+	)
+	for _, entry := range entries {
+		if entry.IsDir {
+			continue
+		}
+		size += entry.Size
+		if size > localModMaxExtractedSize {
+			return "", fmt.Errorf("解压后的模组超出大小限制")
+		}
+		if entry.Name == "modinfo.lua" {
+			return "", nil
+		}
+		dir, file := filepath.Split(entry.Name)
+		if file == "modinfo.lua" && strings.Count(strings.Trim(dir, "/"), "/") == 0 {
+			roots = append(roots, strings.Trim(dir, "/"))
+		}
+	}
+
+	switch len(roots) {
+	case 0:
+		return "", fmt.Errorf("zip中没有找到modinfo.lua")
+	case 1:
+		return roots[0], nil
+	default:
+		return "", fmt.Errorf("zip中包含多个modinfo.lua")
+	}
+}
+
+// installLocalModArchive 解压并校验本地模组，modinfo.lua解析成功后才会替换已安装的版本，调用方需持有localModMutex
+func installLocalModArchive(zipPath, name, lang string) (*LocalModInfo, error) {
+	if !ValidLocalModName(name) {
+		return &LocalModInfo{}, fmt.Errorf("模组目录名不合法: %s", name)
+	}
+
+	root, err := findModInfoInZip(zipPath)
+	if err != nil {
+		return &LocalModInfo{}, err
+	}
+	modinfo, err := utils.ReadZipFile(zipPath, filepath.Join(root, "modinfo.lua"), localModMaxLua)
+	if err != nil {
+		return &LocalModInfo{}, err
+	}
+
+	tmp := fmt.Sprintf("%s/%s/mods/local/%s/%s", db.CurrentDir, utils.DmpFiles, localModTempDir, name)
+	_ = utils.RemoveDir(tmp)
+	defer func() {
+		if err := utils.RemoveDir(tmp); err != nil {
+			logger.Logger.Error("清理本地模组临时目录失败", "err", err)
+		}
+	}()
+	if err = utils.EnsureDirExists(tmp); err != nil {
+		return &LocalModInfo{}, err
+	}
+	modinfoPath := fmt.Sprintf("%s/modinfo.lua", tmp)
+	if err = os.WriteFile(modinfoPath, modinfo, 0644); err != nil {
+		return &LocalModInfo{}, err
+	}
+
+	parser, err := NewModInfoParser(modinfoPath, 0)
+	if err != nil {
+		return &LocalModInfo{}, err
+	}
+	if err = parser.Parse(lang); err != nil {
+		return &LocalModInfo{}, fmt.Errorf("modinfo.lua解析失败: %w", err)
+	}
+
+	unzipPath := fmt.Sprintf("%s/unzip", tmp)
+	// zip中记录的大小不可信，解压时再限制实际写入的大小
+	if err = utils.UnzipWithLimit(zipPath, unzipPath, localModMaxExtractedSize); err != nil {
+		return &LocalModInfo{}, err
+	}
+
+	// 先复制到临时目录再替换，避免游戏读取到不完整的模组
+	dest := localModPath(name)
+	newDest, oldDest := dest+".new", dest+".old"
+	_ = utils.RemoveDir(newDest)
+	_ = utils.RemoveDir(oldDest)
+	if err = os.CopyFS(newDest, os.DirFS(filepath.Join(unzipPath, root))); err != nil {
+		_ = utils.RemoveDir(newDest)
+		return &LocalModInfo{}, err
+	}
+	if utils.FileDirectoryExists(dest) {
+		if err = os.Rename(dest, oldDest); err != nil {
+			_ = utils.RemoveDir(newDest)
+			return &LocalModInfo{}, err
+		}
+	}
+	if err = os.Rename(newDest, dest); err != nil {
+		_ = os.Rename(oldDest, dest)
+		_ = utils.RemoveDir(newDest)
+		return &LocalModInfo{}, err
+	}
+	_ = utils.RemoveDir(oldDest)
+
+	version := parser.Version
+	if version == "" {
+		version = "0"
+	}
+	size, _ := utils.GetDirSize(dest)
+
+	return &LocalModInfo{
+		Name:    name,
+		Title:   parser.Name,
+		Version: version,
+		Size:    size,
+	}, nil
+}
+
+// InstallLocalMod 安装上传的本地模组，写入数据库后需要调用SaveLocalModArchive保存zip
+func InstallLocalMod(zipPath, name, lang string) (*LocalModInfo, error) {
+	localModMutex.Lock()
+	defer localModMutex.Unlock()
+
+	return installLocalModArchive(zipPath, name, lang)
+}
+
+// SaveLocalModArchive 将zip保存为数据库记录对应的版本，同一版本重复上传会覆盖
+func SaveLocalModArchive(zipPath, name string, id int) error {
+	localModMutex.Lock()
+	defer localModMutex.Unlock()
+
+	if err := utils.EnsureDirExists(localModArchiveDir(name)); err != nil {
+		return err
+	}
+	content, err := os.ReadFile(zipPath)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(LocalModArchivePath(name, id), content, 0644)
+}
+
+// ActivateLocalModVersion 重新安装已保存的历史版本，兼容以版本号命名的旧zip
+func ActivateLocalModVersion(name, version string, id int, lang string) (*LocalModInfo, error) {
+	localModMutex.Lock()
+	defer localModMutex.Unlock()
+
+	archive := LocalModArchivePath(name, id)
+	if !utils.FileDirectoryExists(archive) {
+		archive = legacyLocalModArchivePath(name, version)
+	}
+	if !utils.FileDirectoryExists(archive) {
+		return &LocalModInfo{}, fmt.Errorf("本地模组%s没有版本%s", name, version)
+	}
+
+	return installLocalModArchive(archive, name, lang)
+}
+
+// RemoveLocalMod 删除本地模组及所有历史版本
+func RemoveLocalMod(name string) error {
+	if !ValidLocalModName(name) {
+		return fmt.Errorf("模组目录名不合法: %s", name)
+	}
+
+	localModMutex.Lock()
+	defer localModMutex.Unlock()
+
+	if err := utils.RemoveDir(localModPath(name)); err != nil {
+		return err
+	}
+
+	return utils.RemoveDir(localModArchiveDir(name))
+}
+
+// isLocalModKey modoverrides中除创意工坊模组和禁本地配置外的key都是本地模组
+func isLocalModKey(key string) bool {
+	return key != "client_mods_disabled" && !strings.HasPrefix(key, "workshop-")
+}
+
+// localModEnable 启用本地模组，和创意工坊模组一样使用默认配置写入所有世界的modoverrides
+func (g *Game) localModEnable(name string) error {
+	if !ValidLocalModName(name) || !utils.FileDirectoryExists(localModPath(name)) {
+		return fmt.Errorf("本地模组%s未安装", name)
+	}
+
+	if err := g.eventBackup(BackupEventMod); err != nil {
+		logger.Logger.Error("模组启用前备份失败", "err", err)
+	}
+
+	parser, err := NewModInfoParser(fmt.Sprintf("%s/modinfo.lua", localModPath(name)), 0)
+	if err != nil {
+		return err
+	}
+	if err = parser.Parse(g.lang); err != nil {
+		return err
+	}
+
+	if err = g.addModKeyConfig(name, *parser.Configuration, nil, nil); err != nil {
+		return err
+	}
+
+	return g.saveMods()
+}
+
+// localModDisable 禁用本地模组
+func (g *Game) localModDisable(name string) error {
+	return g.modDisableKey(name)
+}
+
+// getEnabledLocalMods 获取房间或世界已启用的本地模组
+func (g *Game) getEnabledLocalMods(worldID int) ([]string, error) {
+	modORContent, err := g.getModOverrides(worldID)
+	if err != nil || modORContent == "" {
+		return []string{}, err
+	}
+
+	modORParser := NewModORParser()
+	defer modORParser.close()
+
+	mods, err := modORParser.Parse(modORContent, g.lang)
+	if err != nil {
+		return []string{}, err
+	}
+
+	names := []string{}
+	for key := range mods {
+		if isLocalModKey(key) {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// isLocalModEnabled 房间或任一世界是否启用了本地模组
+func (g *Game) isLocalModEnabled(name string) (bool, error) {
+	worldIDs := []int{0}
+	if !g.room.ModInOne {
+		worldIDs = []int{}
+		for _, world := range *g.worlds {
+			worldIDs = append(worldIDs, world.ID)
+		}
+	}
+
+	for _, worldID := range worldIDs {
+		names, err := g.getEnabledLocalMods(worldID)
+		if err != nil {
+			return false, err
+		}
+		if utils.Contains(names, name) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
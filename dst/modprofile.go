@@ -0,0 +1,162 @@
+package dst
+
+import (
+	"dst-management-platform-api/logger"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ParseModOverrides 解析modoverrides.lua内容，内容为空时返回空集合
+func ParseModOverrides(content, lang string) (ModORCollection, error) {
+	if strings.TrimSpace(content) == "" {
+		return make(ModORCollection), nil
+	}
+
+	modORParser := NewModORParser()
+	defer modORParser.close()
+
+	return modORParser.Parse(content, lang)
+}
+
+// FormatModOverrides 校验并重新生成modoverrides.lua内容
+func FormatModOverrides(content, lang string) (string, error) {
+	mods, err := ParseModOverrides(content, lang)
+	if err != nil {
+		return "", err
+	}
+
+	return mods.ToLuaCode(), nil
+}
+
+const (
+	ModDiffAdded   = "added"
+	ModDiffRemoved = "removed"
+	ModDiffChanged = "changed"
+)
+
+type ModOptionDiff struct {
+	Key string `json:"key"`
+	Old any    `json:"old"`
+	New any    `json:"new"`
+}
+
+type ModDiff struct {
+	Key        string          `json:"key"`
+	ModID      int             `json:"modID"`
+	Type       string          `json:"type"`
+	OldEnabled bool            `json:"oldEnabled"`
+	NewEnabled bool            `json:"newEnabled"`
+	Options    []ModOptionDiff `json:"options"`
+}
+
+// DiffModCollections 对比两份模组配置，返回新增、删除和修改的模组
+func DiffModCollections(oldMods, newMods ModORCollection) []ModDiff {
+	keys := make(map[string]bool)
+	for key := range oldMods {
+		keys[key] = true
+	}
+	for key := range newMods {
+		keys[key] = true
+	}
+	var sortedKeys []string
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	diffs := []ModDiff{}
+	for _, key := range sortedKeys {
+		oldMod, inOld := oldMods[key]
+		newMod, inNew := newMods[key]
+		modID, _ := ParseWorkshopKey(key)
+		diff := ModDiff{
+			Key:     key,
+			ModID:   modID,
+			Options: []ModOptionDiff{},
+		}
+
+		switch {
+		case !inOld:
+			diff.Type = ModDiffAdded
+			diff.NewEnabled = newMod.Enabled
+		case !inNew:
+			diff.Type = ModDiffRemoved
+			diff.OldEnabled = oldMod.Enabled
+		default:
+			diff.Type = ModDiffChanged
+			diff.OldEnabled = oldMod.Enabled
+			diff.NewEnabled = newMod.Enabled
+			diff.Options = diffModOptions(oldMod.ConfigurationOptions, newMod.ConfigurationOptions)
+			if diff.OldEnabled == diff.NewEnabled && len(diff.Options) == 0 {
+				continue
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return diffs
+}
+
+func diffModOptions(oldOptions, newOptions map[string]any) []ModOptionDiff {
+	keys := make(map[string]bool)
+	for key := range oldOptions {
+		keys[key] = true
+	}
+	for key := range newOptions {
+		keys[key] = true
+	}
+	var sortedKeys []string
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	var diffs []ModOptionDiff
+	for _, key := range sortedKeys {
+		oldValue, newValue := oldOptions[key], newOptions[key]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			diffs = append(diffs, ModOptionDiff{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+
+	return diffs
+}
+
+// getModOverrides 获取房间或世界的modoverrides.lua内容
+func (g *Game) getModOverrides(worldID int) (string, error) {
+	if g.room.ModInOne {
+		return g.room.ModData, nil
+	}
+
+	for _, world := range *g.worlds {
+		if world.ID == worldID {
+			return world.ModData, nil
+		}
+	}
+
+	return "", fmt.Errorf("世界%d不存在", worldID)
+}
+
+// applyModOverrides 使用指定的modoverrides.lua内容覆盖房间或世界的模组配置，worldIDs为空则应用到所有世界
+func (g *Game) applyModOverrides(content string, worldIDs []int) error {
+	if err := g.eventBackup(BackupEventMod); err != nil {
+		logger.Logger.Error("应用模组配置前备份失败", "err", err)
+	}
+
+	if g.room.ModInOne {
+		g.room.ModData = content
+	} else {
+		worlds := *g.worlds
+		for i, world := range worlds {
+			if len(worldIDs) != 0 && !slices.Contains(worldIDs, world.ID) {
+				continue
+			}
+			worlds[i].ModData = content
+		}
+	}
+
+	return g.saveMods()
+}
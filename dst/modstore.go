@@ -0,0 +1,332 @@
+package dst
+
+import (
+	"crypto/sha256"
+	"dst-management-platform-api/database/db"
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 共享模组存储：dmp_files/mods/store/{modID}/{hash}
+// 各房间世界的 dst/ugc_mods/{cluster}/{world}/content/322330/{modID} 为指向共享存储的软链接
+// 存储中的目录只读，模组更新后生成新的hash目录，没有链接引用的目录会被清理
+
+var modStoreMutex sync.Mutex
+
+func modStoreRoot() string {
+	return fmt.Sprintf("%s/%s/mods/store", db.CurrentDir, utils.DmpFiles)
+}
+
+// hashModDir 计算模组目录的内容哈希，包含相对路径和文件内容
+func hashModDir(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			_, _ = io.WriteString(h, rel+"/\x00")
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, _ = io.WriteString(h, rel+"\x00")
+		_, err = io.Copy(h, file)
+
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// storeModDir 将模组目录存入共享存储，内容相同的模组只保存一份，调用方需持有modStoreMutex
+func storeModDir(src string, modID int) (string, error) {
+	hash, err := hashModDir(src)
+	if err != nil {
+		return "", err
+	}
+
+	dest := fmt.Sprintf("%s/%d/%s", modStoreRoot(), modID, hash)
+	if utils.FileDirectoryExists(dest) {
+		return dest, nil
+	}
+
+	if err = utils.EnsureDirExists(filepath.Dir(dest)); err != nil {
+		return "", err
+	}
+	tmp := dest + ".tmp"
+	_ = utils.RemoveDir(tmp)
+	if err = utils.BashCMD(fmt.Sprintf("cp -r %s %s", src, tmp)); err != nil {
+		_ = utils.RemoveDir(tmp)
+		return "", err
+	}
+	if err = os.Rename(tmp, dest); err != nil {
+		_ = utils.RemoveDir(tmp)
+		return "", err
+	}
+
+	return dest, nil
+}
+
+// linkModToWorlds 将共享存储中的模组链接到房间的所有世界，调用方需持有modStoreMutex
+func (g *Game) linkModToWorlds(modID int, storePath string) error {
+	for _, world := range g.worldSaveData {
+		gamePath := fmt.Sprintf("%s/%s/content/322330/%d", g.ugcPath, world.WorldName, modID)
+		if err := utils.RemoveDir(gamePath); err != nil {
+			return err
+		}
+		if err := utils.EnsureDirExists(filepath.Dir(gamePath)); err != nil {
+			return err
+		}
+		if err := os.Symlink(storePath, gamePath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// storeAndLinkMod 将下载的模组存入共享存储并链接到所有世界，返回共享存储路径
+func (g *Game) storeAndLinkMod(modID int, src string) (string, error) {
+	modStoreMutex.Lock()
+	defer modStoreMutex.Unlock()
+
+	storePath, err := storeModDir(src, modID)
+	if err != nil {
+		return "", err
+	}
+
+	return storePath, g.linkModToWorlds(modID, storePath)
+}
+
+type ModStoreRef struct {
+	RoomID      int    `json:"roomID"`
+	ClusterName string `json:"clusterName"`
+	WorldName   string `json:"worldName"`
+}
+
+type ModStoreUsage struct {
+	ModID      int           `json:"modID"`
+	Hash       string        `json:"hash"`
+	Shared     bool          `json:"shared"` // false为未迁移到共享存储的独立目录
+	Size       int64         `json:"size"`
+	SavedSize  int64         `json:"savedSize"` // 相比每个世界单独保存节省的空间
+	References []ModStoreRef `json:"references"`
+}
+
+type ModStoreReport struct {
+	TotalSize    int64           `json:"totalSize"`
+	SavedSize    int64           `json:"savedSize"`
+	UnsharedSize int64           `json:"unsharedSize"`
+	Mods         []ModStoreUsage `json:"mods"`
+}
+
+type worldModDir struct {
+	path  string
+	modID int
+	ref   ModStoreRef
+}
+
+// scanWorldModDirs 扫描所有房间世界的ugc模组目录
+func scanWorldModDirs() ([]worldModDir, error) {
+	paths, err := filepath.Glob(fmt.Sprintf("%s/dst/ugc_mods/*/*/content/322330/*", db.CurrentDir))
+	if err != nil {
+		return []worldModDir{}, err
+	}
+
+	var dirs []worldModDir
+	for _, path := range paths {
+		modID, err := strconv.Atoi(filepath.Base(path))
+		if err != nil {
+			continue
+		}
+		worldPath := filepath.Dir(filepath.Dir(filepath.Dir(path)))
+		clusterName := filepath.Base(filepath.Dir(worldPath))
+		roomID, _ := strconv.Atoi(strings.TrimPrefix(clusterName, "Cluster_"))
+		dirs = append(dirs, worldModDir{
+			path:  path,
+			modID: modID,
+			ref: ModStoreRef{
+				RoomID:      roomID,
+				ClusterName: clusterName,
+				WorldName:   filepath.Base(worldPath),
+			},
+		})
+	}
+
+	return dirs, nil
+}
+
+// GetModStoreUsage 统计每个模组版本的磁盘占用和引用
+func GetModStoreUsage() (*ModStoreReport, error) {
+	modStoreMutex.Lock()
+	defer modStoreMutex.Unlock()
+
+	dirs, err := scanWorldModDirs()
+	if err != nil {
+		return &ModStoreReport{}, err
+	}
+
+	report := &ModStoreReport{Mods: []ModStoreUsage{}}
+	refs := make(map[string][]ModStoreRef)
+	for _, dir := range dirs {
+		info, err := os.Lstat(dir.path)
+		if err != nil {
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(dir.path)
+			if err == nil {
+				refs[target] = append(refs[target], dir.ref)
+			}
+			continue
+		}
+		// 未迁移的独立目录
+		size, _ := utils.GetDirSize(dir.path)
+		report.UnsharedSize += size
+		report.TotalSize += size
+		report.Mods = append(report.Mods, ModStoreUsage{
+			ModID:      dir.modID,
+			Size:       size,
+			References: []ModStoreRef{dir.ref},
+		})
+	}
+
+	storePaths, err := filepath.Glob(fmt.Sprintf("%s/*/*", modStoreRoot()))
+	if err != nil {
+		return report, err
+	}
+	for _, storePath := range storePaths {
+		if strings.HasSuffix(storePath, ".tmp") {
+			continue
+		}
+		modID, err := strconv.Atoi(filepath.Base(filepath.Dir(storePath)))
+		if err != nil {
+			continue
+		}
+		size, _ := utils.GetDirSize(storePath)
+		usage := ModStoreUsage{
+			ModID:      modID,
+			Hash:       filepath.Base(storePath),
+			Shared:     true,
+			Size:       size,
+			References: refs[storePath],
+		}
+		if usage.References == nil {
+			usage.References = []ModStoreRef{}
+		}
+		if len(usage.References) > 1 {
+			usage.SavedSize = size * int64(len(usage.References)-1)
+		}
+		report.TotalSize += size
+		report.SavedSize += usage.SavedSize
+		report.Mods = append(report.Mods, usage)
+	}
+
+	sort.SliceStable(report.Mods, func(i, j int) bool {
+		return report.Mods[i].Size > report.Mods[j].Size
+	})
+
+	return report, nil
+}
+
+// PruneModStore 删除共享存储中没有被任何世界引用的模组版本，返回释放的空间
+func PruneModStore() (int64, error) {
+	modStoreMutex.Lock()
+	defer modStoreMutex.Unlock()
+
+	dirs, err := scanWorldModDirs()
+	if err != nil {
+		return 0, err
+	}
+	referenced := make(map[string]bool)
+	for _, dir := range dirs {
+		if target, err := os.Readlink(dir.path); err == nil {
+			referenced[target] = true
+		}
+	}
+
+	storePaths, err := filepath.Glob(fmt.Sprintf("%s/*/*", modStoreRoot()))
+	if err != nil {
+		return 0, err
+	}
+
+	var freed int64
+	for _, storePath := range storePaths {
+		if referenced[storePath] {
+			continue
+		}
+		size, _ := utils.GetDirSize(storePath)
+		if err = utils.RemoveDir(storePath); err != nil {
+			logger.Logger.Error("删除共享模组失败", "err", err, "path", storePath)
+			continue
+		}
+		freed += size
+		logger.Logger.Info("删除未引用的共享模组", "path", storePath)
+
+		// 模组的所有版本都被删除后，删除模组目录
+		modDir := filepath.Dir(storePath)
+		if entries, err := os.ReadDir(modDir); err == nil && len(entries) == 0 {
+			_ = utils.RemoveDir(modDir)
+		}
+	}
+
+	return freed, nil
+}
+
+// MigrateModStore 将各个世界中独立保存的模组迁移到共享存储，返回迁移的目录数量
+func MigrateModStore() (int, error) {
+	modStoreMutex.Lock()
+	defer modStoreMutex.Unlock()
+
+	dirs, err := scanWorldModDirs()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, dir := range dirs {
+		info, err := os.Lstat(dir.path)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		storePath, err := storeModDir(dir.path, dir.modID)
+		if err != nil {
+			logger.Logger.Error("迁移模组到共享存储失败", "err", err, "path", dir.path)
+			continue
+		}
+		if err = utils.RemoveDir(dir.path); err != nil {
+			logger.Logger.Error("删除模组目录失败", "err", err, "path", dir.path)
+			continue
+		}
+		if err = os.Symlink(storePath, dir.path); err != nil {
+			// 链接失败时复制回去，保证模组可用
+			logger.Logger.Error("链接共享模组失败", "err", err, "path", dir.path)
+			_ = utils.BashCMD(fmt.Sprintf("cp -r %s %s", storePath, dir.path))
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
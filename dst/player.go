@@ -4,9 +4,18 @@ import (
 	"dst-management-platform-api/logger"
 	"dst-management-platform-api/utils"
 	"fmt"
+	"regexp"
 	"strings"
 )
 
+// uidPattern 拼接到控制台命令中的UID只允许KU_开头的字母数字
+var uidPattern = regexp.MustCompile(`^KU_[A-Za-z0-9_-]+$`)
+
+// ValidUID 校验是否为合法的KU_ UID
+func ValidUID(uid string) bool {
+	return uidPattern.MatchString(uid)
+}
+
 type playerSaveData struct {
 	whitelist     []string
 	blocklist     []string
@@ -112,3 +121,128 @@ func (g *Game) removePlayerList(uid, listType string) error {
 
 	return fmt.Errorf("类型错误")
 }
+
+// setBlocklist 使用生效中的封禁和平台级黑名单重新生成blocklist.txt，并记录合并进来的平台级UID
+func (g *Game) setBlocklist(uids, global []string) error {
+	g.playerSaveData.blocklist = append(append([]string{}, uids...), global...)
+	if err := g.savePlayerList(); err != nil {
+		return err
+	}
+
+	state := g.globalPlayerListState()
+	state.Blocklist = global
+
+	return utils.StructToJsonFile(g.globalPlayerListStatePath(), state)
+}
+
+// localBlocklist blocklist.txt中不是由平台级黑名单合并进来的UID，包括旧版本、上传存档和手动添加的UID
+func (g *Game) localBlocklist() []string {
+	state := g.globalPlayerListState()
+
+	uids := []string{}
+	for _, uid := range utils.RemoveDuplicates(g.playerSaveData.blocklist) {
+		if ValidUID(uid) && !utils.Contains(state.Blocklist, uid) {
+			uids = append(uids, uid)
+		}
+	}
+
+	return uids
+}
+
+// banPlayer 在运行中的世界封禁玩家，在线的玩家会被立即踢出，duration为0时永久封禁，单位秒
+func (g *Game) banPlayer(uid string, duration int64) error {
+	if !ValidUID(uid) {
+		return fmt.Errorf("UID格式错误: %s", uid)
+	}
+
+	cmd := fmt.Sprintf("TheNet:Kick('%s') TheNet:Ban('%s')", uid, uid)
+	if duration > 0 {
+		cmd = fmt.Sprintf("TheNet:Kick('%s') TheNet:BanForTime('%s', %d)", uid, uid, duration)
+	}
+
+	// 玩家可能在任意一个世界，需要在所有运行中的世界执行
+	var success bool
+	for _, world := range g.worldSaveData {
+		if err := utils.ScreenCMD(cmd, world.screenName); err == nil {
+			success = true
+		}
+	}
+	if !success {
+		return fmt.Errorf("没有运行中的世界")
+	}
+
+	return nil
+}
+
+// globalPlayerListState 上次合并到房间名单中的平台级UID，用于区分房间自己添加的UID
+type globalPlayerListState struct {
+	Adminlist []string `json:"adminlist"`
+	Whitelist []string `json:"whitelist"`
+	Blocklist []string `json:"blocklist"`
+}
+
+func (g *Game) globalPlayerListStatePath() string {
+	return fmt.Sprintf("%s/dmp_global_lists.json", g.clusterPath)
+}
+
+// globalPlayerListState 读取上次合并的平台级UID，文件不存在时返回空记录
+func (g *Game) globalPlayerListState() globalPlayerListState {
+	var state globalPlayerListState
+	statePath := g.globalPlayerListStatePath()
+	if utils.FileDirectoryExists(statePath) {
+		if err := utils.JsonFileToStruct(statePath, &state); err != nil {
+			logger.Logger.Warn("读取平台级名单记录失败", "err", err)
+		}
+	}
+
+	return state
+}
+
+// mergeGlobalPlayerList 去掉上次合并的平台级UID后再合并新的平台级名单，返回新名单和本次合并的UID
+func mergeGlobalPlayerList(current, lastApplied, global []string) ([]string, []string) {
+	var local []string
+	for _, uid := range current {
+		if !utils.Contains(lastApplied, uid) {
+			local = append(local, uid)
+		}
+	}
+
+	merged := append([]string{}, local...)
+	applied := []string{}
+	for _, uid := range global {
+		if !utils.Contains(local, uid) {
+			merged = append(merged, uid)
+			applied = append(applied, uid)
+		}
+	}
+
+	return merged, applied
+}
+
+// applyGlobalPlayerLists 将平台级管理员名单和白名单合并到房间名单，黑名单由封禁记录统一生成
+func (g *Game) applyGlobalPlayerLists(adminlist, whitelist []string) error {
+	state := g.globalPlayerListState()
+
+	oldWhitelist := utils.RemoveDuplicates(g.playerSaveData.whitelist)
+	newState := globalPlayerListState{Blocklist: state.Blocklist}
+	g.playerSaveData.adminlist, newState.Adminlist = mergeGlobalPlayerList(g.playerSaveData.adminlist, state.Adminlist, adminlist)
+	g.playerSaveData.whitelist, newState.Whitelist = mergeGlobalPlayerList(g.playerSaveData.whitelist, state.Whitelist, whitelist)
+	// 平台级白名单移除的UID可能同时是VIP，需要重新合并VIP名单
+	if err := g.mergeVipList(); err != nil {
+		return err
+	}
+
+	if err := g.savePlayerList(); err != nil {
+		return err
+	}
+	if err := utils.StructToJsonFile(g.globalPlayerListStatePath(), newState); err != nil {
+		return err
+	}
+
+	// 白名单数量变化需要重新生成cluster.ini
+	if len(utils.RemoveDuplicates(g.playerSaveData.whitelist)) != len(oldWhitelist) {
+		return g.createRoom()
+	}
+
+	return nil
+}
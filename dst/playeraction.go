@@ -0,0 +1,137 @@
+package dst
+
+import (
+	"dst-management-platform-api/utils"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+const (
+	PlayerActionKick     = "kick"
+	PlayerActionBan      = "ban" // 封禁需要记录到数据库，由scheduler.BanPlayers处理
+	PlayerActionKill     = "kill"
+	PlayerActionRevive   = "revive"
+	PlayerActionDespawn  = "despawn"
+	PlayerActionTeleport = "teleport"
+	PlayerActionGive     = "give"
+	PlayerActionMessage  = "message"
+)
+
+const (
+	playerActionMaxCount      = 500
+	playerActionMaxCoordinate = 5000
+	playerActionMaxMessage    = 200
+)
+
+var prefabRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// luaSafeRegex 可以直接放入Lua单引号字符串的字符，同时不会被bash双引号和screen转义
+var luaSafeRegex = regexp.MustCompile(`^[A-Za-z0-9 _.,:-]*$`)
+
+// PlayerAction 玩家操作参数，TargetUID和坐标只在传送时使用，Prefab和Count只在给予物品时使用
+type PlayerAction struct {
+	Action    string  `json:"action"`
+	UID       string  `json:"uid"`
+	TargetUID string  `json:"targetUID"`
+	X         float64 `json:"x"`
+	Z         float64 `json:"z"`
+	Prefab    string  `json:"prefab"`
+	Count     int     `json:"count"`
+	Message   string  `json:"message"`
+}
+
+// ValidPrefab 检查prefab名称格式
+func ValidPrefab(prefab string) bool {
+	return prefabRegex.MatchString(prefab)
+}
+
+// ValidatePlayerAction 检查玩家操作参数
+func ValidatePlayerAction(action PlayerAction) error {
+	_, err := buildPlayerActionCmd(action)
+	return err
+}
+
+// luaString 生成Lua字符串表达式，包含特殊字符时使用string.char，避免拼接出额外的Lua代码
+func luaString(s string) string {
+	if luaSafeRegex.MatchString(s) {
+		return "'" + s + "'"
+	}
+
+	var codes []string
+	for _, b := range []byte(s) {
+		codes = append(codes, fmt.Sprintf("%d", b))
+	}
+
+	return fmt.Sprintf("string.char(%s)", strings.Join(codes, ","))
+}
+
+// buildPlayerActionCmd 校验参数并生成Lua命令，玩家不在当前世界时命令不做任何操作
+func buildPlayerActionCmd(action PlayerAction) (string, error) {
+	if !ValidUID(action.UID) {
+		return "", fmt.Errorf("UID格式错误: %s", action.UID)
+	}
+
+	var body string
+	switch action.Action {
+	case PlayerActionKick:
+		return fmt.Sprintf("TheNet:Kick('%s')", action.UID), nil
+	case PlayerActionKill:
+		body = "if p.components.health and not p.components.health:IsDead() then p.components.health:Kill() end"
+	case PlayerActionRevive:
+		body = "if p:HasTag('playerghost') then p:PushEvent('respawnfromghost') end"
+	case PlayerActionDespawn:
+		body = "c_despawn(p)"
+	case PlayerActionTeleport:
+		if action.TargetUID != "" {
+			if !ValidUID(action.TargetUID) {
+				return "", fmt.Errorf("UID格式错误: %s", action.TargetUID)
+			}
+			body = fmt.Sprintf("local t = UserToPlayer('%s') if t then c_goto(t, p) end", action.TargetUID)
+			break
+		}
+		if math.IsNaN(action.X) || math.IsNaN(action.Z) || math.Abs(action.X) > playerActionMaxCoordinate || math.Abs(action.Z) > playerActionMaxCoordinate {
+			return "", fmt.Errorf("坐标超出范围")
+		}
+		body = fmt.Sprintf("c_teleport(%.2f, 0, %.2f, p)", action.X, action.Z)
+	case PlayerActionGive:
+		if !ValidPrefab(action.Prefab) {
+			return "", fmt.Errorf("prefab格式错误: %s", action.Prefab)
+		}
+		if action.Count < 1 || action.Count > playerActionMaxCount {
+			return "", fmt.Errorf("数量超出范围")
+		}
+		body = fmt.Sprintf("if PrefabExists('%s') then for i = 1, %d do local item = SpawnPrefab('%s') if item then p.components.inventory:GiveItem(item) end end end", action.Prefab, action.Count, action.Prefab)
+	case PlayerActionMessage:
+		// 饥荒没有私聊接口，使用角色说话的方式提示玩家
+		if action.Message == "" || len([]rune(action.Message)) > playerActionMaxMessage {
+			return "", fmt.Errorf("消息长度错误")
+		}
+		body = fmt.Sprintf("if p.components.talker then p.components.talker:Say(%s, 8) end", luaString(action.Message))
+	default:
+		return "", fmt.Errorf("不支持的操作: %s", action.Action)
+	}
+
+	return fmt.Sprintf("local p = UserToPlayer('%s') if p then %s end", action.UID, body), nil
+}
+
+// playerAction 在运行中的世界执行玩家操作，玩家可能在任意一个世界，需要在所有运行中的世界执行
+func (g *Game) playerAction(action PlayerAction) error {
+	cmd, err := buildPlayerActionCmd(action)
+	if err != nil {
+		return err
+	}
+
+	var success bool
+	for _, world := range g.worldSaveData {
+		if err = utils.ScreenCMD(cmd, world.screenName); err == nil {
+			success = true
+		}
+	}
+	if !success {
+		return fmt.Errorf("没有运行中的世界")
+	}
+
+	return nil
+}
@@ -0,0 +1,300 @@
+package dst
+
+import (
+	"dst-management-platform-api/utils"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// dayTime 游戏中一天的时长，单位秒
+const dayTime = 480
+
+// PlayerStateItem 物品，Slot为物品栏序号或装备栏位置，Items为容器中的物品
+type PlayerStateItem struct {
+	Slot   string            `json:"slot"`
+	Prefab string            `json:"prefab"`
+	Count  int               `json:"count"`
+	Items  []PlayerStateItem `json:"items,omitempty"`
+}
+
+// PlayerState 玩家状态，Source为console时是在线玩家的实时数据，为save时是离线玩家最后一次存档的数据，存档中没有上限值
+type PlayerState struct {
+	UID       string            `json:"uid"`
+	Prefab    string            `json:"prefab"`
+	Source    string            `json:"source"`
+	WorldID   int               `json:"worldID"`
+	WorldName string            `json:"worldName"`
+	IsGhost   bool              `json:"isGhost"`
+	Health    float64           `json:"health"`
+	MaxHealth float64           `json:"maxHealth"`
+	Hunger    float64           `json:"hunger"`
+	MaxHunger float64           `json:"maxHunger"`
+	Sanity    float64           `json:"sanity"`
+	MaxSanity float64           `json:"maxSanity"`
+	AgeDays   float64           `json:"ageDays"`
+	Inventory []PlayerStateItem `json:"inventory"`
+	Equip     []PlayerStateItem `json:"equip"`
+	UpdatedAt int64             `json:"updatedAt"`
+}
+
+// playerStateLua 在世界中查询玩家状态并输出json，命令中不能出现双引号，空表会被编码为对象，所以不输出空的物品列表
+const playerStateLua = `(function() local p = UserToPlayer('%s') if p == nil then return 'nil' end ` +
+	`local function item(s, v) local r = {slot = tostring(s), prefab = v.prefab, count = v.components.stackable and v.components.stackable:StackSize() or 1} ` +
+	`if v.components.container then local its = {} for k, c in pairs(v.components.container.slots) do table.insert(its, item(k, c)) end if #its > 0 then r.items = its end end return r end ` +
+	`local inv, equip = {}, {} if p.components.inventory then ` +
+	`for k, v in pairs(p.components.inventory.itemslots) do table.insert(inv, item(k, v)) end ` +
+	`for k, v in pairs(p.components.inventory.equipslots) do table.insert(equip, item(k, v)) end end ` +
+	`local h, u, s = p.components.health, p.components.hunger, p.components.sanity ` +
+	`return json.encode({prefab = p.prefab, isGhost = p:HasTag('playerghost'), ` +
+	`health = h and h.currenthealth or 0, maxHealth = h and h.maxhealth or 0, ` +
+	`hunger = u and u.current or 0, maxHunger = u and u.max or 0, ` +
+	`sanity = s and s.current or 0, maxSanity = s and s.max or 0, ` +
+	`ageDays = p.components.age and p.components.age:GetAgeInDays() or 0, inventory = #inv > 0 and inv or nil, equip = #equip > 0 and equip or nil}) end)()`
+
+// playerState 获取玩家状态，在线玩家通过控制台查询，离线玩家读取存档中的玩家文件
+func (g *Game) playerState(uid string) (*PlayerState, error) {
+	if !ValidUID(uid) {
+		return nil, fmt.Errorf("UID格式错误: %s", uid)
+	}
+
+	if state, err := g.playerStateFromConsole(uid); err == nil {
+		return state, nil
+	}
+
+	return g.playerStateFromSave(uid)
+}
+
+func (g *Game) playerStateFromConsole(uid string) (*PlayerState, error) {
+	cmd := fmt.Sprintf(playerStateLua, uid)
+	for _, world := range g.worldSaveData {
+		identifier := fmt.Sprintf("DMP_PLAYER_STATE_%d", time.Now().UnixNano())
+		logPath := fmt.Sprintf("%s/server_log.txt", world.worldPath)
+		out, err := utils.ScreenCMDOutput(cmd, identifier, world.screenName, logPath)
+		if err != nil || out == "nil" {
+			continue
+		}
+
+		var state PlayerState
+		if err = json.Unmarshal([]byte(out), &state); err != nil {
+			return nil, err
+		}
+		state.UID = uid
+		state.Source = "console"
+		state.WorldID = world.ID
+		state.WorldName = world.WorldName
+		state.UpdatedAt = utils.GetTimestamp()
+		sortPlayerStateItems(state.Inventory)
+		if state.Inventory == nil {
+			state.Inventory = []PlayerStateItem{}
+		}
+		if state.Equip == nil {
+			state.Equip = []PlayerStateItem{}
+		}
+
+		return &state, nil
+	}
+
+	return nil, fmt.Errorf("玩家不在线")
+}
+
+// playerStateFromSave 在所有世界的最新存档中查找玩家文件，玩家最后所在的世界的文件最新
+// 玩家目录名为UID加下划线，开启encode_user_path后目录名会被编码，无法匹配时返回错误
+func (g *Game) playerStateFromSave(uid string) (*PlayerState, error) {
+	var (
+		latestFile  string
+		latestTime  time.Time
+		latestWorld worldSaveData
+	)
+
+	for _, world := range g.worldSaveData {
+		metaFile, err := findLatestMetaFile(world.sessionPath)
+		if err != nil {
+			continue
+		}
+		sessionDir := filepath.Dir(metaFile)
+		entries, err := os.ReadDir(sessionDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || !strings.EqualFold(strings.TrimSuffix(entry.Name(), "_"), uid) {
+				continue
+			}
+			file, modTime, err := findLatestPlayerFile(filepath.Join(sessionDir, entry.Name()))
+			if err != nil {
+				continue
+			}
+			if modTime.After(latestTime) {
+				latestFile = file
+				latestTime = modTime
+				latestWorld = world
+			}
+		}
+	}
+
+	if latestFile == "" {
+		return nil, fmt.Errorf("未找到玩家存档")
+	}
+
+	state, err := parsePlayerSaveFile(latestFile)
+	if err != nil {
+		return nil, err
+	}
+	state.UID = uid
+	state.Source = "save"
+	state.WorldID = latestWorld.ID
+	state.WorldName = latestWorld.WorldName
+	state.UpdatedAt = latestTime.UnixMilli()
+
+	return state, nil
+}
+
+// findLatestPlayerFile 获取玩家目录下最新的存档文件
+func findLatestPlayerFile(directory string) (string, time.Time, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var (
+		latestFile string
+		latestTime time.Time
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) == ".meta" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latestTime) {
+			latestFile = filepath.Join(directory, entry.Name())
+			latestTime = info.ModTime()
+		}
+	}
+	if latestFile == "" {
+		return "", time.Time{}, fmt.Errorf("玩家目录中没有存档文件")
+	}
+
+	return latestFile, latestTime, nil
+}
+
+// parsePlayerSaveFile 解析玩家存档文件，文件内容为返回玩家数据表的Lua代码
+func parsePlayerSaveFile(path string) (*PlayerState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	L := lua.NewState()
+	defer L.Close()
+
+	content := strings.TrimRight(string(data), "\x00")
+	if err = L.DoString(content); err != nil {
+		return nil, err
+	}
+	tbl, ok := L.Get(-1).(*lua.LTable)
+	if !ok {
+		return nil, fmt.Errorf("玩家存档格式错误")
+	}
+
+	state := PlayerState{
+		Inventory: []PlayerStateItem{},
+		Equip:     []PlayerStateItem{},
+	}
+	state.Prefab = luaTableString(tbl, "prefab")
+
+	components, ok := tbl.RawGetString("data").(*lua.LTable)
+	if !ok {
+		return &state, nil
+	}
+	if health, ok := components.RawGetString("health").(*lua.LTable); ok {
+		state.Health = luaTableNumber(health, "health")
+	}
+	if hunger, ok := components.RawGetString("hunger").(*lua.LTable); ok {
+		state.Hunger = luaTableNumber(hunger, "hunger")
+	}
+	if sanity, ok := components.RawGetString("sanity").(*lua.LTable); ok {
+		state.Sanity = luaTableNumber(sanity, "current")
+	}
+	if age, ok := components.RawGetString("age").(*lua.LTable); ok {
+		state.AgeDays = luaTableNumber(age, "age") / dayTime
+	}
+	state.IsGhost = components.RawGetString("is_ghost") == lua.LTrue
+	if inventory, ok := components.RawGetString("inventory").(*lua.LTable); ok {
+		state.Inventory = parseSaveItems(inventory.RawGetString("items"))
+		state.Equip = parseSaveItems(inventory.RawGetString("equip"))
+	}
+
+	return &state, nil
+}
+
+// parseSaveItems 解析存档中的物品表，键为物品栏序号或装备栏位置
+func parseSaveItems(lv lua.LValue) []PlayerStateItem {
+	items := []PlayerStateItem{}
+	tbl, ok := lv.(*lua.LTable)
+	if !ok {
+		return items
+	}
+
+	tbl.ForEach(func(key, value lua.LValue) {
+		record, ok := value.(*lua.LTable)
+		if !ok {
+			return
+		}
+		item := PlayerStateItem{
+			Slot:   key.String(),
+			Prefab: luaTableString(record, "prefab"),
+			Count:  1,
+		}
+		if data, ok := record.RawGetString("data").(*lua.LTable); ok {
+			if stackable, ok := data.RawGetString("stackable").(*lua.LTable); ok {
+				if stack := int(luaTableNumber(stackable, "stack")); stack > 0 {
+					item.Count = stack
+				}
+			}
+			if container, ok := data.RawGetString("container").(*lua.LTable); ok {
+				item.Items = parseSaveItems(container.RawGetString("items"))
+			}
+		}
+		items = append(items, item)
+	})
+	sortPlayerStateItems(items)
+
+	return items
+}
+
+// sortPlayerStateItems 按物品栏序号排序，装备栏位置按名称排序
+func sortPlayerStateItems(items []PlayerStateItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, errA := strconv.Atoi(items[i].Slot)
+		b, errB := strconv.Atoi(items[j].Slot)
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return items[i].Slot < items[j].Slot
+	})
+}
+
+func luaTableString(tbl *lua.LTable, key string) string {
+	if v, ok := tbl.RawGetString(key).(lua.LString); ok {
+		return string(v)
+	}
+	return ""
+}
+
+func luaTableNumber(tbl *lua.LTable, key string) float64 {
+	if v, ok := tbl.RawGetString(key).(lua.LNumber); ok {
+		return float64(v)
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+package dst
+
+import (
+	"dst-management-platform-api/database/db"
+	"dst-management-platform-api/utils"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PlayerStatSample 玩家存活天数和距离上次采样的死亡次数
+type PlayerStatSample struct {
+	UID    string `json:"uid"`
+	Days   int    `json:"days"`
+	Deaths int    `json:"deaths"`
+}
+
+// playerStatsLua 给玩家注册死亡监听，第一项输出世界启动时生成的会话标识，之后输出uid,天数,累计死亡次数，已离开当前世界的玩家天数为-1
+// 游戏内不清空计数，由Go记录上次读取的值计算差值，避免输出后读取失败导致死亡次数丢失
+// 世界重启后监听和计数会丢失，会话标识随之变化，下次采样时重新注册
+const playerStatsLua = `(function() DMP_DEATHS = DMP_DEATHS or {} DMP_STATS_SESSION = DMP_STATS_SESSION or tostring(os.time()) ` +
+	`local r = {DMP_STATS_SESSION} local seen = {} for _, p in ipairs(AllPlayers) do ` +
+	`if not p.dmp_death_listener then p.dmp_death_listener = true p:ListenForEvent('death', function(inst) DMP_DEATHS[inst.userid] = (DMP_DEATHS[inst.userid] or 0) + 1 end) end ` +
+	`local days = p.components.age and math.floor(p.components.age:GetAgeInDays()) or 0 ` +
+	`table.insert(r, p.userid .. ',' .. days .. ',' .. (DMP_DEATHS[p.userid] or 0)) seen[p.userid] = true end ` +
+	`for uid, n in pairs(DMP_DEATHS) do if not seen[uid] then table.insert(r, uid .. ',-1,' .. n) end end return table.concat(r, ';') end)()`
+
+// samplePlayerStats 采样所有运行中的世界的玩家存活天数和死亡次数
+func (g *Game) samplePlayerStats() []PlayerStatSample {
+	var samples []PlayerStatSample
+
+	for _, world := range g.worldSaveData {
+		identifier := fmt.Sprintf("DMP_PLAYER_STATS_%d", time.Now().UnixNano())
+		logPath := fmt.Sprintf("%s/server_log.txt", world.worldPath)
+		out, err := utils.ScreenCMDOutput(playerStatsLua, identifier, world.screenName, logPath)
+		if err != nil || out == "" {
+			continue
+		}
+
+		records := strings.Split(out, ";")
+		session := records[0]
+		deaths := make(map[string]int)
+		var worldSamples []PlayerStatSample
+		for _, record := range records[1:] {
+			fields := strings.Split(record, ",")
+			if len(fields) != 3 || !ValidUID(fields[0]) {
+				continue
+			}
+			days, errDays := strconv.Atoi(fields[1])
+			total, errDeaths := strconv.Atoi(fields[2])
+			if errDays != nil || errDeaths != nil {
+				continue
+			}
+			deaths[fields[0]] = total
+			worldSamples = append(worldSamples, PlayerStatSample{UID: fields[0], Days: days, Deaths: total})
+		}
+
+		samples = append(samples, diffPlayerDeaths(world.ID, session, deaths, worldSamples)...)
+	}
+
+	return samples
+}
+
+// diffPlayerDeaths 将累计死亡次数换算为距离上次采样的死亡次数，并记录本次的累计值
+// 平台重启后第一次采样没有上次的记录，只作为基准，不计入死亡次数
+func diffPlayerDeaths(worldID int, session string, deaths map[string]int, samples []PlayerStatSample) []PlayerStatSample {
+	db.PlayerDeathCountsMutex.Lock()
+	defer db.PlayerDeathCountsMutex.Unlock()
+
+	last, ok := db.PlayerDeathCounts[worldID]
+	for i := range samples {
+		switch {
+		case !ok:
+			samples[i].Deaths = 0
+		case last.Session != session:
+			// 世界重启，累计次数从0开始
+		default:
+			samples[i].Deaths = max(samples[i].Deaths-last.Deaths[samples[i].UID], 0)
+		}
+	}
+	db.PlayerDeathCounts[worldID] = db.PlayerDeathCount{Session: session, Deaths: deaths}
+
+	return samples
+}
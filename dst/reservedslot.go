@@ -0,0 +1,107 @@
+package dst
+
+import (
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+	"encoding/json"
+	"fmt"
+)
+
+// ReservedSlotSetting 预留位设置
+// 饥荒只为whitelist.txt中的玩家保留位置，VIP名单会合并到白名单，白名单中的玩家和VIP共用预留位
+type ReservedSlotSetting struct {
+	Slots      int      `json:"slots"`      // 预留位数量，不再根据白名单人数生成
+	VipList    []string `json:"vipList"`    // VIP名单，与白名单分开维护
+	KickNonVip bool     `json:"kickNonVip"` // 满员时VIP加入后踢出最近加入的非VIP玩家
+}
+
+// ParseReservedSlotSetting 解析预留位设置
+func ParseReservedSlotSetting(reservedSlotSetting string) (ReservedSlotSetting, error) {
+	setting := ReservedSlotSetting{
+		VipList: []string{},
+	}
+	if reservedSlotSetting == "" {
+		return setting, nil
+	}
+	if err := json.Unmarshal([]byte(reservedSlotSetting), &setting); err != nil {
+		return setting, err
+	}
+	if setting.Slots < 0 {
+		return setting, fmt.Errorf("预留位数量错误")
+	}
+	for _, uid := range setting.VipList {
+		if !ValidUID(uid) {
+			return setting, fmt.Errorf("UID格式错误: %s", uid)
+		}
+	}
+	if setting.VipList == nil {
+		setting.VipList = []string{}
+	}
+
+	return setting, nil
+}
+
+// reservedSlotSetting 获取生效中的预留位设置，未开启或设置错误时返回false
+func (g *Game) reservedSlotSetting() (ReservedSlotSetting, bool) {
+	if g.setting == nil || !g.setting.ReservedSlotEnable {
+		return ReservedSlotSetting{}, false
+	}
+	setting, err := ParseReservedSlotSetting(g.setting.ReservedSlotSetting)
+	if err != nil {
+		logger.Logger.Warn("预留位设置错误", "err", err, "room", g.room.ID)
+		return ReservedSlotSetting{}, false
+	}
+
+	return setting, true
+}
+
+// whitelistSlots 开启预留位后使用设置的数量，否则与白名单人数一致
+func (g *Game) whitelistSlots() int {
+	setting, ok := g.reservedSlotSetting()
+	if !ok {
+		return len(g.whitelist)
+	}
+	if setting.Slots > g.room.MaxPlayer {
+		return g.room.MaxPlayer
+	}
+
+	return setting.Slots
+}
+
+// vipListState 上次合并到白名单中的VIP，用于区分白名单自己添加的UID
+type vipListState struct {
+	Viplist []string `json:"viplist"`
+}
+
+// mergeVipList 将VIP名单合并到内存中的白名单，未开启预留位时移除上次合并的VIP，调用方负责保存名单
+func (g *Game) mergeVipList() error {
+	statePath := fmt.Sprintf("%s/dmp_vip_list.json", g.clusterPath)
+	var state vipListState
+	if utils.FileDirectoryExists(statePath) {
+		if err := utils.JsonFileToStruct(statePath, &state); err != nil {
+			logger.Logger.Warn("读取VIP名单记录失败", "err", err)
+		}
+	}
+
+	var vipList []string
+	if setting, ok := g.reservedSlotSetting(); ok {
+		vipList = setting.VipList
+	}
+
+	var newState vipListState
+	g.playerSaveData.whitelist, newState.Viplist = mergeGlobalPlayerList(g.playerSaveData.whitelist, state.Viplist, vipList)
+
+	return utils.StructToJsonFile(statePath, newState)
+}
+
+// applyReservedSlots 将VIP名单合并到白名单并保存，cluster.ini由调用方重新生成
+func (g *Game) applyReservedSlots() error {
+	if err := utils.EnsureDirExists(g.clusterPath); err != nil {
+		return err
+	}
+	if err := g.mergeVipList(); err != nil {
+		return err
+	}
+
+	return g.savePlayerList()
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +16,13 @@ import (
 	lua "github.com/yuin/gopher-lua"
 )
 
+const (
+	// 回档前通知玩家的等待时间（秒）
+	rollbackNoticeSeconds = 5
+	// 回档后校验天数的超时时间
+	rollbackVerifyTimeout = 3 * time.Minute
+)
+
 type roomSaveData struct {
 	// dir
 	clusterName string
@@ -116,7 +125,7 @@ vote_kick_enabled = ` + strconv.FormatBool(g.room.Vote) + `
 lan_only_cluster = ` + strconv.FormatBool(g.room.Lan) + `
 offline_cluster = ` + strconv.FormatBool(g.room.Offline) + `
 cluster_description = ` + g.room.Description + `
-whitelist_slots = ` + strconv.Itoa(len(g.whitelist)) + `
+whitelist_slots = ` + strconv.Itoa(g.whitelistSlots()) + `
 cluster_name = ` + g.room.GameName + `
 cluster_password = ` + g.room.Password + `
 cluster_language = ` + lang + `
@@ -139,6 +148,10 @@ cluster_key = ` + g.room.ClusterKey + steamGroupSetting
 }
 
 func (g *Game) reset(force bool) error {
+	if err := g.eventBackup(BackupEventReset); err != nil {
+		logger.Logger.Error("重置前备份失败", "err", err)
+	}
+
 	if force {
 		defer func() {
 			_ = g.startAllWorld()
@@ -222,6 +235,17 @@ func (g *Game) sessionInfo() *RoomSessionInfo {
 		return &roomSessionInfo
 	}
 
+	return parseSessionMeta(sessionPath)
+}
+
+// parseSessionMeta 解析存档的.meta文件
+func parseSessionMeta(sessionPath string) *RoomSessionInfo {
+	roomSessionInfo := RoomSessionInfo{
+		Season: "error",
+		Cycles: -1,
+		Phase:  "error",
+	}
+
 	// 读取二进制文件
 	data, err := os.ReadFile(sessionPath)
 	if err != nil {
@@ -233,6 +257,9 @@ func (g *Game) sessionInfo() *RoomSessionInfo {
 	defer L.Close()
 
 	// 将文件内容作为 Lua 代码执行
+	if len(data) == 0 {
+		return &roomSessionInfo
+	}
 	content := string(data)
 	content = content[:len(content)-1]
 
@@ -335,10 +362,16 @@ func (g *Game) backup() error {
 		return err
 	}
 
+	g.saveBackupFingerprint()
+
 	return nil
 }
 
 func (g *Game) restore(filename string) (*SaveJson, error) {
+	if err := g.eventBackup(BackupEventRestore); err != nil {
+		logger.Logger.Error("恢复前备份失败", "err", err)
+	}
+
 	zipPath := fmt.Sprintf("%s/backup/%d", utils.DmpFiles, g.room.ID)
 	filePath := fmt.Sprintf("%s/%s", zipPath, filename)
 	err := utils.Unzip(filePath, zipPath)
@@ -382,6 +415,7 @@ type BackupFile struct {
 	TimeStamp int    `json:"timestamp"`
 	Size      int64  `json:"size"`
 	FileName  string `json:"fileName"`
+	Pinned    bool   `json:"pinned"`
 }
 
 func (g *Game) getBackups() ([]BackupFile, error) {
@@ -453,6 +487,160 @@ func (g *Game) deleteBackups(filenames []string) int {
 	return s
 }
 
+// 备份中允许在线查看的文本文件
+var backupTextFileExts = []string{".ini", ".lua", ".json", ".txt"}
+
+// 备份中允许在线查看的最大文件大小
+const backupTextFileMaxSize = 1024 * 1024
+
+type BackupTreeNode struct {
+	Name     string            `json:"name"`
+	Path     string            `json:"path"`
+	IsDir    bool              `json:"isDir"`
+	Size     int64             `json:"size"`
+	Children []*BackupTreeNode `json:"children,omitempty"`
+}
+
+func (g *Game) getBackupFilePath(filename string) (string, error) {
+	if filename == "" || filepath.Base(filename) != filename {
+		return "", fmt.Errorf("无效的备份文件名: %s", filename)
+	}
+
+	return fmt.Sprintf("%s/backup/%d/%s", utils.DmpFiles, g.room.ID, filename), nil
+}
+
+// getBackupTree 获取备份文件的目录树
+func (g *Game) getBackupTree(filename string) ([]*BackupTreeNode, error) {
+	zipFilePath, err := g.getBackupFilePath(filename)
+	if err != nil {
+		return []*BackupTreeNode{}, err
+	}
+
+	entries, err := utils.ListZip(zipFilePath)
+	if err != nil {
+		return []*BackupTreeNode{}, err
+	}
+
+	root := &BackupTreeNode{IsDir: true}
+	nodes := map[string]*BackupTreeNode{"": root}
+
+	// 获取节点，不存在的父目录会自动创建
+	var getNode func(path string) *BackupTreeNode
+	getNode = func(path string) *BackupTreeNode {
+		if node, ok := nodes[path]; ok {
+			return node
+		}
+		parentPath := ""
+		if i := strings.LastIndex(path, "/"); i != -1 {
+			parentPath = path[:i]
+		}
+		parent := getNode(parentPath)
+		node := &BackupTreeNode{
+			Name:  filepath.Base(path),
+			Path:  path,
+			IsDir: true,
+		}
+		parent.Children = append(parent.Children, node)
+		nodes[path] = node
+		return node
+	}
+
+	for _, entry := range entries {
+		node := getNode(entry.Name)
+		node.IsDir = entry.IsDir
+		node.Size = entry.Size
+	}
+
+	var sortNodes func(node *BackupTreeNode)
+	sortNodes = func(node *BackupTreeNode) {
+		sort.Slice(node.Children, func(i, j int) bool {
+			if node.Children[i].IsDir != node.Children[j].IsDir {
+				return node.Children[i].IsDir
+			}
+			return node.Children[i].Name < node.Children[j].Name
+		})
+		for _, child := range node.Children {
+			sortNodes(child)
+		}
+	}
+	sortNodes(root)
+
+	if root.Children == nil {
+		return []*BackupTreeNode{}, nil
+	}
+
+	return root.Children, nil
+}
+
+// readBackupFile 读取备份中的文本文件
+func (g *Game) readBackupFile(filename, path string) (string, error) {
+	zipFilePath, err := g.getBackupFilePath(filename)
+	if err != nil {
+		return "", err
+	}
+
+	if !utils.Contains(backupTextFileExts, strings.ToLower(filepath.Ext(path))) {
+		return "", fmt.Errorf("不支持查看的文件类型: %s", path)
+	}
+
+	data, err := utils.ReadZipFile(zipFilePath, path, backupTextFileMaxSize)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// extractBackupFile 将备份中的单个文件解压到当前存档，世界配置文件会同步到房间信息中
+func (g *Game) extractBackupFile(filename, path string) error {
+	zipFilePath, err := g.getBackupFilePath(filename)
+	if err != nil {
+		return err
+	}
+
+	// 备份中的路径为 Cluster_<id>/...，去掉第一层目录
+	parts := strings.SplitN(filepath.ToSlash(filepath.Clean(path)), "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("无效的文件路径: %s", path)
+	}
+	target := parts[1]
+
+	if target == "dmp.json" {
+		return fmt.Errorf("不支持解压的文件: %s", path)
+	}
+
+	err = utils.UnzipFile(zipFilePath, path, g.clusterPath, target)
+	if err != nil {
+		return err
+	}
+
+	targetPath := filepath.Join(g.clusterPath, target)
+	for i, world := range g.worldSaveData {
+		switch targetPath {
+		case filepath.Clean(world.modOverridesPath):
+			content, err := utils.GetFileAllContent(targetPath)
+			if err != nil {
+				return err
+			}
+			if g.room.ModInOne {
+				g.room.ModData = content
+			} else {
+				(*g.worlds)[i].ModData = content
+			}
+			return g.saveMods()
+		case filepath.Clean(world.levelDataOverridePath):
+			content, err := utils.GetFileAllContent(targetPath)
+			if err != nil {
+				return err
+			}
+			(*g.worlds)[i].LevelData = content
+			return nil
+		}
+	}
+
+	return nil
+}
+
 func findLatestMetaFile(directory string) (string, error) {
 	// 检查指定目录是否存在
 	_, err := os.Stat(directory)
@@ -574,3 +762,239 @@ func (g *Game) deleteSnapshot(filename string) error {
 
 	return nil
 }
+
+type RollbackResult struct {
+	Count          int  `json:"count"`
+	ExpectedCycles int  `json:"expectedCycles"`
+	CurrentCycles  int  `json:"currentCycles"`
+	Verified       bool `json:"verified"`
+}
+
+// getRollbackCount 计算回档到指定存档需要的c_rollback参数，所有世界的存档必须一致
+func (g *Game) getRollbackCount(filename string) (int, error) {
+	count := 0
+
+	for _, world := range g.worldSaveData {
+		sessionID, err := getSessionID(world.savePath)
+		if err != nil {
+			return 0, err
+		}
+
+		files, err := getSnapshotFiles(fmt.Sprintf("%s/%s", world.sessionPath, sessionID))
+		if err != nil {
+			return 0, err
+		}
+
+		// 最新的存档排在最前面
+		sort.Slice(files, func(i, j int) bool {
+			if files[i].ModTime.Equal(files[j].ModTime) {
+				return files[i].Name > files[j].Name
+			}
+			return files[i].ModTime.After(files[j].ModTime)
+		})
+
+		index := -1
+		for i, file := range files {
+			if file.Name == filename {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return 0, fmt.Errorf("世界%s中未找到存档文件%s", world.WorldName, filename)
+		}
+
+		if count == 0 {
+			count = index + 1
+		} else if count != index+1 {
+			return 0, fmt.Errorf("世界%s的存档与其他世界不一致", world.WorldName)
+		}
+	}
+
+	if count == 0 {
+		return 0, fmt.Errorf("未找到存档文件%s", filename)
+	}
+
+	return count, nil
+}
+
+// getCurrentCycles 通过控制台获取当前运行中的天数
+func (g *Game) getCurrentCycles(world *worldSaveData) (int, error) {
+	ts := time.Now().UnixNano()
+	cmd := fmt.Sprintf("print('==== DMP Cycles [%d] ' .. TheWorld.state.cycles .. ' Cycles DMP ====')", ts)
+	err := utils.ScreenCMD(cmd, world.screenName)
+	if err != nil {
+		return 0, err
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	re := regexp.MustCompile(fmt.Sprintf(`==== DMP Cycles \[%d\] (\d+) Cycles DMP ====`, ts))
+	data := utils.GetFileLastNLines(fmt.Sprintf("%s/server_log.txt", world.worldPath), 100)
+	for i := len(data) - 1; i >= 0; i-- {
+		if matches := re.FindStringSubmatch(data[i]); matches != nil {
+			return strconv.Atoi(matches[1])
+		}
+	}
+
+	return 0, fmt.Errorf("未找到天数信息")
+}
+
+// startRollback 同步校验存档后在后台回档，回档需要等待通知和天数校验，done在回档结束后调用
+func (g *Game) startRollback(filename string, done func(*RollbackResult, error)) (*RollbackResult, error) {
+	count, err := g.getRollbackCount(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	master := g.worldSaveData[0]
+	for _, world := range g.worldSaveData {
+		if world.IsMaster {
+			master = world
+			break
+		}
+	}
+
+	if !g.worldUpStatus(master.ID) {
+		return nil, fmt.Errorf("主世界未运行")
+	}
+
+	result := RollbackResult{
+		Count:          count,
+		ExpectedCycles: -1,
+		CurrentCycles:  -1,
+	}
+
+	sessionID, err := getSessionID(master.savePath)
+	if err != nil {
+		return nil, err
+	}
+	metaInfo := parseSessionMeta(fmt.Sprintf("%s/%s/%s.meta", master.sessionPath, sessionID, filename))
+	result.ExpectedCycles = metaInfo.Cycles
+
+	go func() {
+		err := g.rollback(&master, &result)
+		if done != nil {
+			done(&result, err)
+		}
+	}()
+
+	return &result, nil
+}
+
+// rollback 先通知玩家，执行c_rollback后校验天数
+func (g *Game) rollback(master *worldSaveData, result *RollbackResult) error {
+	var notice string
+	switch g.lang {
+	case "en":
+		notice = fmt.Sprintf("The server will roll back to day %d in %d seconds", result.ExpectedCycles+1, rollbackNoticeSeconds)
+	default:
+		notice = fmt.Sprintf("服务器将在%d秒后回档至第%d天", rollbackNoticeSeconds, result.ExpectedCycles+1)
+	}
+	err := g.systemMsg(notice)
+	if err != nil {
+		logger.Logger.Warn("发送回档通知失败", "err", err)
+	}
+	time.Sleep(rollbackNoticeSeconds * time.Second)
+
+	err = utils.ScreenCMD(fmt.Sprintf("c_rollback(%d)", result.Count), master.screenName)
+	if err != nil {
+		return err
+	}
+
+	// 回档后世界会重新加载，轮询确认天数
+	if result.ExpectedCycles < 0 {
+		return nil
+	}
+	deadline := time.Now().Add(rollbackVerifyTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(5 * time.Second)
+		cycles, err := g.getCurrentCycles(master)
+		if err != nil {
+			continue
+		}
+		result.CurrentCycles = cycles
+		if cycles == result.ExpectedCycles {
+			result.Verified = true
+			break
+		}
+	}
+
+	return nil
+}
+
+type CloneOption struct {
+	Save       bool `json:"save"`
+	Mod        bool `json:"mod"`
+	PlayerList bool `json:"playerList"`
+}
+
+// cloneTo 将当前房间复制到目标房间，目标房间的世界需要与当前房间一一对应
+func (g *Game) cloneTo(target *Game, option CloneOption) error {
+	if len(target.worldSaveData) != len(g.worldSaveData) {
+		return fmt.Errorf("目标房间世界个数与当前房间不一致")
+	}
+
+	var err error
+
+	if option.PlayerList {
+		target.adminlist = append([]string{}, g.adminlist...)
+		target.whitelist = append([]string{}, g.whitelist...)
+		target.blocklist = append([]string{}, g.blocklist...)
+		err = target.savePlayerList()
+		if err != nil {
+			return err
+		}
+		// 平台级名单和VIP名单的合并记录也需要复制，否则会被当作房间自己添加的UID
+		for _, stateFile := range []string{"dmp_global_lists.json", "dmp_vip_list.json"} {
+			statePath := fmt.Sprintf("%s/%s", g.clusterPath, stateFile)
+			if utils.FileDirectoryExists(statePath) {
+				err = utils.BashCMD(fmt.Sprintf("cp %s %s/", statePath, target.clusterPath))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// 生成配置文件，whitelist_slots会根据复制的白名单或预留位设置生成
+	err = target.createRoom()
+	if err != nil {
+		return err
+	}
+	err = target.createWorlds()
+	if err != nil {
+		return err
+	}
+
+	if option.Save {
+		for i, world := range g.worldSaveData {
+			if _, err = os.Stat(world.savePath); os.IsNotExist(err) {
+				continue
+			}
+			cmd := fmt.Sprintf("rm -rf %s && cp -r %s %s", target.worldSaveData[i].savePath, world.savePath, target.worldSaveData[i].savePath)
+			logger.Logger.Debug(cmd)
+			err = utils.BashCMD(cmd)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if option.Mod {
+		if _, err = os.Stat(g.ugcPath); err == nil {
+			err = utils.EnsureDirExists(filepath.Dir(target.ugcPath))
+			if err != nil {
+				return err
+			}
+			cmd := fmt.Sprintf("rm -rf %s && cp -r %s %s", target.ugcPath, g.ugcPath, target.ugcPath)
+			logger.Logger.Debug(cmd)
+			err = utils.BashCMD(cmd)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
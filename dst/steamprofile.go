@@ -0,0 +1,162 @@
+package dst
+
+import (
+	"bufio"
+	"dst-management-platform-api/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// steamSummaryBatch GetPlayerSummaries每次最多查询100个SteamID
+const steamSummaryBatch = 100
+
+var (
+	// steamIDRegex 64位SteamID
+	steamIDRegex = regexp.MustCompile(`^7656119\d{10}$`)
+	// steamConnectionRegex 玩家连接时的日志，如 New incoming connection 1.2.3.4|10999 <76561198000000000>
+	steamConnectionRegex = regexp.MustCompile(`New incoming connection \S+ <(\d+)>`)
+	// clientAuthenticatedRegex 玩家认证通过时的日志，如 Client authenticated: (KU_xxxxxxxx) nickname
+	clientAuthenticatedRegex = regexp.MustCompile(`Client authenticated: \((KU_[A-Za-z0-9_-]+)\)`)
+)
+
+type SteamPlayerSummary struct {
+	SteamID     string `json:"steamid"`
+	PersonaName string `json:"personaname"`
+	ProfileURL  string `json:"profileurl"`
+	AvatarFull  string `json:"avatarfull"`
+	TimeCreated int64  `json:"timecreated"` // 单位秒，资料未公开时没有该字段
+}
+
+// SteamProfileClient Steam玩家资料API客户端，SummaryURL可以指向本地的模拟服务
+type SteamProfileClient struct {
+	SummaryURL string
+	APIKey     func() string
+	HTTPClient *http.Client
+}
+
+// SteamProfile 默认的Steam玩家资料API客户端
+var SteamProfile = &SteamProfileClient{
+	SummaryURL: utils.SteamApiPlayerSummary,
+	APIKey:     utils.GetSteamApiKey,
+	HTTPClient: &http.Client{
+		Timeout: utils.HttpTimeout * time.Second,
+	},
+}
+
+// ValidSteamID 校验是否为64位SteamID
+func ValidSteamID(steamID string) bool {
+	return steamIDRegex.MatchString(steamID)
+}
+
+// GetPlayerSummaries 批量获取Steam玩家资料，返回以SteamID为键的资料，不存在的SteamID不会出现在结果中
+func (s *SteamProfileClient) GetPlayerSummaries(steamIDs []string) (map[string]SteamPlayerSummary, error) {
+	summaries := make(map[string]SteamPlayerSummary)
+
+	for start := 0; start < len(steamIDs); start += steamSummaryBatch {
+		end := min(start+steamSummaryBatch, len(steamIDs))
+
+		query := url.Values{}
+		if s.APIKey != nil {
+			query.Set("key", s.APIKey())
+		}
+		query.Set("steamids", strings.Join(steamIDs[start:end], ","))
+
+		players, err := s.getPlayerSummaries(query)
+		if err != nil {
+			return summaries, err
+		}
+		for _, player := range players {
+			summaries[player.SteamID] = player
+		}
+	}
+
+	return summaries, nil
+}
+
+func (s *SteamProfileClient) getPlayerSummaries(query url.Values) ([]SteamPlayerSummary, error) {
+	httpResponse, err := s.HTTPClient.Get(s.SummaryURL + "?" + query.Encode())
+	if err != nil {
+		return []SteamPlayerSummary{}, err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return []SteamPlayerSummary{}, fmt.Errorf("获取Steam玩家资料失败，HTTP代码：%s", httpResponse.Status)
+	}
+
+	var jsonResp struct {
+		Response struct {
+			Players []SteamPlayerSummary `json:"players"`
+		} `json:"response"`
+	}
+	if err = json.NewDecoder(httpResponse.Body).Decode(&jsonResp); err != nil {
+		return []SteamPlayerSummary{}, err
+	}
+
+	return jsonResp.Response.Players, nil
+}
+
+// steamIDsFromLog 从所有世界的server_log.txt中获取玩家的SteamID，返回以UID为键的SteamID
+// 饥荒只在玩家连接时输出SteamID，认证通过的日志紧随其后，WeGame玩家和日志被清理的玩家无法获取
+func (g *Game) steamIDsFromLog() map[string]string {
+	steamIDs := make(map[string]string)
+
+	for _, world := range g.worldSaveData {
+		for uid, steamID := range parseSteamIDsFromLog(fmt.Sprintf("%s/server_log.txt", world.worldPath)) {
+			steamIDs[uid] = steamID
+		}
+	}
+
+	return steamIDs
+}
+
+func parseSteamIDsFromLog(logPath string) map[string]string {
+	steamIDs := make(map[string]string)
+
+	file, err := os.Open(logPath)
+	if err != nil {
+		return steamIDs
+	}
+	defer file.Close()
+
+	var (
+		pending string
+		// 有连接等待认证时又有新连接，认证日志无法对应到SteamID，跳过这些连接的认证日志
+		skip int
+	)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := steamConnectionRegex.FindStringSubmatch(line); match != nil {
+			switch {
+			case skip > 0:
+				skip++
+			case pending != "":
+				pending = ""
+				skip = 2
+			default:
+				pending = match[1]
+			}
+			continue
+		}
+		if match := clientAuthenticatedRegex.FindStringSubmatch(line); match != nil {
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if ValidSteamID(pending) {
+				steamIDs[match[1]] = pending
+			}
+			pending = ""
+		}
+	}
+
+	return steamIDs
+}
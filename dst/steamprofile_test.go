@@ -0,0 +1,137 @@
+package dst
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newSteamProfileStub 模拟GetPlayerSummaries接口，summaries以SteamID为键
+func newSteamProfileStub(t *testing.T, status int, summaries map[string]SteamPlayerSummary) (*SteamProfileClient, *steamAPIStub) {
+	t.Helper()
+
+	stub := newSteamAPIStub(t, status, func(query url.Values) any {
+		players := []SteamPlayerSummary{}
+		for _, steamID := range strings.Split(query.Get("steamids"), ",") {
+			if summary, ok := summaries[steamID]; ok {
+				players = append(players, summary)
+			}
+		}
+		return map[string]any{
+			"response": map[string]any{"players": players},
+		}
+	})
+
+	return &SteamProfileClient{
+		SummaryURL: stub.URL,
+		APIKey:     func() string { return "test-key" },
+		HTTPClient: stub.Client,
+	}, stub
+}
+
+func TestSteamGetPlayerSummaries(t *testing.T) {
+	client, stub := newSteamProfileStub(t, http.StatusOK, map[string]SteamPlayerSummary{
+		"76561198000000001": {SteamID: "76561198000000001", PersonaName: "Wilson", AvatarFull: "a.jpg", TimeCreated: 1300000000},
+		"76561198000000002": {SteamID: "76561198000000002", PersonaName: "Willow"},
+	})
+
+	summaries, err := client.GetPlayerSummaries([]string{"76561198000000001", "76561198000000002", "76561198000000003"})
+	if err != nil {
+		t.Fatalf("GetPlayerSummaries: %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 summaries, got %d", len(summaries))
+	}
+	if summaries["76561198000000001"].PersonaName != "Wilson" || summaries["76561198000000001"].TimeCreated != 1300000000 {
+		t.Errorf("unexpected summary: %+v", summaries["76561198000000001"])
+	}
+	if _, ok := summaries["76561198000000003"]; ok {
+		t.Errorf("missing steam id should not be returned")
+	}
+
+	queries := stub.Queries()
+	if len(queries) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(queries))
+	}
+	query := queries[0]
+	if query.Get("key") != "test-key" {
+		t.Errorf("expected api key in query, got %q", query.Get("key"))
+	}
+	if query.Get("steamids") != "76561198000000001,76561198000000002,76561198000000003" {
+		t.Errorf("unexpected steamids: %q", query.Get("steamids"))
+	}
+}
+
+func TestSteamGetPlayerSummariesBatch(t *testing.T) {
+	client, stub := newSteamProfileStub(t, http.StatusOK, map[string]SteamPlayerSummary{})
+
+	var steamIDs []string
+	for i := 0; i < steamSummaryBatch*2+1; i++ {
+		steamIDs = append(steamIDs, fmt.Sprintf("7656119800%07d", i))
+	}
+	if _, err := client.GetPlayerSummaries(steamIDs); err != nil {
+		t.Fatalf("GetPlayerSummaries: %v", err)
+	}
+
+	queries := stub.Queries()
+	if len(queries) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(queries))
+	}
+	for i, expected := range []int{steamSummaryBatch, steamSummaryBatch, 1} {
+		if n := len(strings.Split(queries[i].Get("steamids"), ",")); n != expected {
+			t.Errorf("request %d: expected %d steam ids, got %d", i, expected, n)
+		}
+	}
+}
+
+func TestSteamGetPlayerSummariesHTTPError(t *testing.T) {
+	client, _ := newSteamProfileStub(t, http.StatusForbidden, nil)
+
+	if _, err := client.GetPlayerSummaries([]string{"76561198000000001"}); err == nil {
+		t.Fatal("expected error on non-200 response")
+	}
+}
+
+func TestParseSteamIDsFromLog(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "server_log.txt")
+	content := strings.Join([]string{
+		"[00:01:00]: New incoming connection 1.2.3.4|10999 <76561198000000001>",
+		"[00:01:01]: Client authenticated: (KU_aaaaaaaa) Wilson",
+		"[00:02:00]: New incoming connection 1.2.3.5|10999 <123>",
+		"[00:02:01]: Client authenticated: (KU_bbbbbbbb) WeGame",
+		"[00:03:01]: Client authenticated: (KU_cccccccc) NoConnection",
+	}, "\n")
+	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	steamIDs := parseSteamIDsFromLog(logPath)
+	if len(steamIDs) != 1 || steamIDs["KU_aaaaaaaa"] != "76561198000000001" {
+		t.Errorf("unexpected steam ids: %v", steamIDs)
+	}
+}
+
+func TestParseSteamIDsFromLogInterleaved(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "server_log.txt")
+	content := strings.Join([]string{
+		// 两个玩家同时连接，无法确定认证日志对应的SteamID
+		"[00:01:00]: New incoming connection 1.2.3.4|10999 <76561198000000001>",
+		"[00:01:00]: New incoming connection 1.2.3.5|10999 <76561198000000002>",
+		"[00:01:01]: Client authenticated: (KU_aaaaaaaa) Wilson",
+		"[00:01:01]: Client authenticated: (KU_bbbbbbbb) Willow",
+		"[00:02:00]: New incoming connection 1.2.3.6|10999 <76561198000000003>",
+		"[00:02:01]: Client authenticated: (KU_cccccccc) Wendy",
+	}, "\n")
+	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	steamIDs := parseSteamIDsFromLog(logPath)
+	if len(steamIDs) != 1 || steamIDs["KU_cccccccc"] != "76561198000000003" {
+		t.Errorf("unexpected steam ids: %v", steamIDs)
+	}
+}
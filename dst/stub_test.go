@@ -0,0 +1,53 @@
+package dst
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// steamAPIStub 模拟Steam Web API的本地服务，记录收到的请求参数
+type steamAPIStub struct {
+	URL    string
+	Client *http.Client
+
+	mutex   sync.Mutex
+	queries []url.Values
+}
+
+// newSteamAPIStub status不为200时直接返回该状态码，否则返回respond生成的JSON
+func newSteamAPIStub(t *testing.T, status int, respond func(query url.Values) any) *steamAPIStub {
+	t.Helper()
+
+	stub := &steamAPIStub{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		stub.mutex.Lock()
+		stub.queries = append(stub.queries, query)
+		stub.mutex.Unlock()
+
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(respond(query))
+	}))
+	t.Cleanup(server.Close)
+
+	stub.URL = server.URL
+	stub.Client = server.Client()
+
+	return stub
+}
+
+// Queries 已收到的请求参数
+func (s *steamAPIStub) Queries() []url.Values {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]url.Values{}, s.queries...)
+}
@@ -1,6 +1,7 @@
 package dst
 
 import (
+	"context"
 	"dst-management-platform-api/database/db"
 	"dst-management-platform-api/database/models"
 	"dst-management-platform-api/logger"
@@ -411,9 +412,24 @@ type ConfigurationOption struct {
 }
 
 type ModInfoParser struct {
-	ModInfoLua    string `json:"modInfoLua"`
-	ModID         int    `json:"modID"`
-	Configuration *[]ConfigurationOption
+	ModInfoLua           string          `json:"modInfoLua"`
+	ModID                int             `json:"modID"`
+	Name                 string          `json:"name"`
+	Version              string          `json:"version"`
+	Dependencies         []ModDependency `json:"dependencies"`
+	Priority             float64         `json:"priority"`
+	APIVersion           int             `json:"apiVersion"`
+	DSTCompatible        bool            `json:"dstCompatible"`
+	ServerOnlyMod        bool            `json:"serverOnlyMod"`
+	AllClientsRequireMod bool            `json:"allClientsRequireMod"`
+	ClientOnlyMod        bool            `json:"clientOnlyMod"`
+	Configuration        *[]ConfigurationOption
+}
+
+// ModDependency 一条模组依赖，IDs中任意一个模组启用即可满足
+type ModDependency struct {
+	IDs   []int    `json:"ids"`
+	Names []string `json:"names"`
 }
 
 func NewModInfoParser(luaPath string, modID int) (*ModInfoParser, error) {
@@ -496,6 +512,20 @@ func convertLuaValue(lv lua.LValue) any {
 	}
 }
 
+// ParseWorkshopKey 解析 workshop-123 格式的模组key
+func ParseWorkshopKey(key string) (int, bool) {
+	idStr, found := strings.CutPrefix(key, "workshop-")
+	if !found {
+		return 0, false
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
+
 func (mf *ModInfoParser) Parse(lang string) error {
 	var options []ConfigurationOption
 
@@ -522,6 +552,54 @@ func (mf *ModInfoParser) Parse(lang string) error {
 		return err
 	}
 
+	// 模组名称和版本
+	if name, ok := L.GetGlobal("name").(lua.LString); ok {
+		mf.Name = string(name)
+	}
+	switch version := L.GetGlobal("version").(type) {
+	case lua.LString:
+		mf.Version = string(version)
+	case lua.LNumber:
+		mf.Version = version.String()
+	}
+
+	// 兼容性标记
+	mf.Priority = float64(lua.LVAsNumber(L.GetGlobal("priority")))
+	mf.APIVersion = int(lua.LVAsNumber(L.GetGlobal("api_version")))
+	if apiVersionDST := int(lua.LVAsNumber(L.GetGlobal("api_version_dst"))); apiVersionDST != 0 {
+		mf.APIVersion = apiVersionDST
+	}
+	mf.DSTCompatible = lua.LVAsBool(L.GetGlobal("dst_compatible"))
+	mf.ServerOnlyMod = lua.LVAsBool(L.GetGlobal("server_only_mod"))
+	mf.AllClientsRequireMod = lua.LVAsBool(L.GetGlobal("all_clients_require_mod"))
+	mf.ClientOnlyMod = lua.LVAsBool(L.GetGlobal("client_only_mod"))
+
+	// 模组依赖，格式为 {{workshop = "workshop-123"}, {["workshop-456"] = false, ["ModName"] = true}}
+	mf.Dependencies = []ModDependency{}
+	if dependencies, ok := L.GetGlobal("mod_dependencies").(*lua.LTable); ok {
+		dependencies.ForEach(func(_ lua.LValue, v lua.LValue) {
+			depTable, ok := v.(*lua.LTable)
+			if !ok {
+				return
+			}
+			dependency := ModDependency{}
+			depTable.ForEach(func(key lua.LValue, value lua.LValue) {
+				name := key.String()
+				if name == "workshop" {
+					name = value.String()
+				}
+				if id, ok := ParseWorkshopKey(name); ok {
+					dependency.IDs = append(dependency.IDs, id)
+				} else {
+					dependency.Names = append(dependency.Names, name)
+				}
+			})
+			if len(dependency.IDs) != 0 || len(dependency.Names) != 0 {
+				mf.Dependencies = append(mf.Dependencies, dependency)
+			}
+		})
+	}
+
 	// 获取 configuration_options 表
 	configOptions := L.GetGlobal("configuration_options")
 	if configOptions.Type() != lua.LTTable {
@@ -956,25 +1034,47 @@ func isValidLuaIdentifier(s string) bool {
 
 func formatLuaKey(s string) string {
 	if len(s) == 0 {
-		return fmt.Sprintf("[\"\"]")
+		return `[""]`
 	}
 
 	// 数字开头
 	numRe := regexp.MustCompile(`^\d`)
 	if numRe.MatchString(s) {
-		return fmt.Sprintf("[\"%s\"]", s)
+		return "[" + quoteLuaString(s) + "]"
 	}
 
 	// 正常变量
 	re := regexp.MustCompile(`[^a-zA-Z0-9_]`)
 	if re.MatchString(s) {
-		return fmt.Sprintf("[\"%s\"]", s)
+		return "[" + quoteLuaString(s) + "]"
 	}
 
 	return s
 }
 
-func downloadNotUGCMod(url string, id int) (error, int64) {
+// progressReader 统计已读取的字节数并回调下载进度
+type progressReader struct {
+	io.Reader
+	bytes    int64
+	total    int64
+	progress ModDownloadProgress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.bytes += int64(n)
+	if r.progress != nil && n > 0 {
+		var percent float64
+		if r.total > 0 {
+			percent = float64(r.bytes) * 100 / float64(r.total)
+		}
+		r.progress(r.bytes, r.total, percent)
+	}
+
+	return n, err
+}
+
+func downloadNotUGCMod(ctx context.Context, url string, id int, progress ModDownloadProgress) (error, int64) {
 	filename := strconv.Itoa(id) + ".zip"              // 临时zip文件名
 	filepath := fmt.Sprintf("dst/mods/%s", filename)   // 临时zip文件路径
 	modPath := fmt.Sprintf("dst/mods/workshop-%d", id) // mod路径
@@ -991,11 +1091,19 @@ func downloadNotUGCMod(url string, id int) (error, int64) {
 	}
 	defer out.Close()
 
+	// 大模组下载时间较长，只限制响应头的超时时间，下载过程由ctx控制
 	client := &http.Client{
-		Timeout: utils.HttpTimeout * time.Second,
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: utils.HttpTimeout * time.Second,
+		},
 	}
 
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err, modSize
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return err, modSize
 	}
@@ -1003,12 +1111,12 @@ func downloadNotUGCMod(url string, id int) (error, int64) {
 
 	// 检查HTTP响应状态码
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("下载mod失败，HTTP代码：" + resp.Status), modSize
+		return fmt.Errorf("下载mod失败，HTTP代码：%s", resp.Status), modSize
 	}
 	// 将响应体写入文件
-	_, err = io.Copy(out, resp.Body)
+	_, err = io.Copy(out, &progressReader{Reader: resp.Body, total: resp.ContentLength, progress: progress})
 	if err != nil {
-		return fmt.Errorf("下载mod失败，HTTP代码：" + err.Error()), modSize
+		return fmt.Errorf("下载mod失败，HTTP代码：%w", err), modSize
 	}
 
 	modSize, err = utils.GetFileSize(filepath)
@@ -0,0 +1,281 @@
+package dst
+
+import (
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// 创意工坊文件类型
+const workshopFileTypeCollection = 2
+
+type WorkshopItem struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	FileSize    string `json:"fileSize"`
+	FileURL     string `json:"fileURL"`
+	PreviewURL  string `json:"previewURL"`
+	TimeUpdated int64  `json:"timeUpdated"`
+}
+
+type WorkshopCollection struct {
+	ID       int            `json:"id"`
+	Title    string         `json:"title"`
+	Children []WorkshopItem `json:"children"`
+}
+
+// WorkshopClient 创意工坊API客户端，DetailURL可以指向本地的模拟服务
+type WorkshopClient struct {
+	DetailURL  string
+	APIKey     func() string
+	HTTPClient *http.Client
+}
+
+// Workshop 默认的创意工坊API客户端
+var Workshop = &WorkshopClient{
+	DetailURL: utils.SteamApiModDetail,
+	APIKey:    utils.GetSteamApiKey,
+	HTTPClient: &http.Client{
+		Timeout: utils.HttpTimeout * time.Second,
+	},
+}
+
+type workshopDetail struct {
+	ID          string `json:"publishedfileid"`
+	Title       string `json:"title"`
+	FileSize    string `json:"file_size"`
+	FileUrl     string `json:"file_url"`
+	PreviewUrl  string `json:"preview_url"`
+	TimeUpdated int64  `json:"time_updated"`
+	FileType    int    `json:"file_type"`
+	Children    []struct {
+		ID        string `json:"publishedfileid"`
+		SortOrder int    `json:"sortorder"`
+	} `json:"children"`
+}
+
+func (w *WorkshopClient) getDetails(ids []int, lang string, includeChildren bool) ([]workshopDetail, error) {
+	language := 0
+	if lang == "zh" {
+		language = 6
+	}
+
+	query := url.Values{}
+	query.Set("language", strconv.Itoa(language))
+	if w.APIKey != nil {
+		query.Set("key", w.APIKey())
+	}
+	if includeChildren {
+		query.Set("includechildren", "true")
+	}
+	for index, id := range ids {
+		query.Set(fmt.Sprintf("publishedfileids[%d]", index), strconv.Itoa(id))
+	}
+
+	httpResponse, err := w.HTTPClient.Get(w.DetailURL + "?" + query.Encode())
+	if err != nil {
+		return []workshopDetail{}, err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return []workshopDetail{}, fmt.Errorf("获取模组信息失败，HTTP代码：%s", httpResponse.Status)
+	}
+
+	var jsonResp struct {
+		Response struct {
+			Publishedfiledetails []workshopDetail `json:"publishedfiledetails"`
+		} `json:"response"`
+	}
+	if err = json.NewDecoder(httpResponse.Body).Decode(&jsonResp); err != nil {
+		return []workshopDetail{}, err
+	}
+
+	return jsonResp.Response.Publishedfiledetails, nil
+}
+
+// GetItems 批量获取创意工坊模组信息
+func (w *WorkshopClient) GetItems(ids []int, lang string) (map[int]WorkshopItem, error) {
+	items := make(map[int]WorkshopItem)
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	details, err := w.getDetails(ids, lang, false)
+	if err != nil {
+		return items, err
+	}
+
+	for _, detail := range details {
+		id, err := strconv.Atoi(detail.ID)
+		if err != nil {
+			continue
+		}
+		items[id] = WorkshopItem{
+			ID:          id,
+			Title:       detail.Title,
+			FileSize:    detail.FileSize,
+			FileURL:     detail.FileUrl,
+			PreviewURL:  detail.PreviewUrl,
+			TimeUpdated: detail.TimeUpdated,
+		}
+	}
+
+	return items, nil
+}
+
+// GetCollection 获取创意工坊合集及合集内的所有模组，按合集中的顺序排列
+func (w *WorkshopClient) GetCollection(collectionID int, lang string) (*WorkshopCollection, error) {
+	details, err := w.getDetails([]int{collectionID}, lang, true)
+	if err != nil {
+		return &WorkshopCollection{}, err
+	}
+	if len(details) == 0 || details[0].FileType != workshopFileTypeCollection {
+		return &WorkshopCollection{}, fmt.Errorf("%d不是创意工坊合集", collectionID)
+	}
+
+	children := details[0].Children
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].SortOrder < children[j].SortOrder
+	})
+
+	var ids []int
+	for _, child := range children {
+		id, err := strconv.Atoi(child.ID)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	items, err := w.GetItems(ids, lang)
+	if err != nil {
+		return &WorkshopCollection{}, err
+	}
+
+	collection := &WorkshopCollection{
+		ID:       collectionID,
+		Title:    details[0].Title,
+		Children: []WorkshopItem{},
+	}
+	for _, id := range ids {
+		item, ok := items[id]
+		if !ok {
+			// 已下架或私有的模组没有详情，保留ID，下载时会报错
+			item = WorkshopItem{ID: id}
+		}
+		collection.Children = append(collection.Children, item)
+	}
+
+	return collection, nil
+}
+
+// GetWorkshopItems 批量获取创意工坊模组信息
+func GetWorkshopItems(ids []int, lang string) (map[int]WorkshopItem, error) {
+	return Workshop.GetItems(ids, lang)
+}
+
+// ModManifestVersion 模组清单格式版本
+const ModManifestVersion = 1
+
+// ModManifest 可分享的模组清单
+type ModManifest struct {
+	Version    int               `json:"version"`
+	Name       string            `json:"name"`
+	ExportedAt int64             `json:"exportedAt"`
+	Mods       []ModManifestItem `json:"mods"`
+}
+
+type ModManifestItem struct {
+	ID                   int            `json:"id"`
+	Name                 string         `json:"name"`
+	ConfigurationOptions map[string]any `json:"configuration_options,omitempty"`
+}
+
+// exportModManifest 导出已启用的模组清单
+func (g *Game) exportModManifest(worldID int, withConfig bool) (*ModManifest, error) {
+	modORParser := NewModORParser()
+	defer modORParser.close()
+
+	var modORContent string
+	if g.room.ModInOne {
+		modORContent = g.room.ModData
+	} else {
+		world, err := g.getWorldByID(worldID)
+		if err != nil {
+			return &ModManifest{}, err
+		}
+		modORContent = world.ModData
+	}
+
+	manifest := &ModManifest{
+		Version:    ModManifestVersion,
+		Name:       g.room.GameName,
+		ExportedAt: utils.GetTimestamp(),
+		Mods:       []ModManifestItem{},
+	}
+	if modORContent == "" {
+		return manifest, nil
+	}
+
+	mods, err := modORParser.Parse(modORContent, g.lang)
+	if err != nil {
+		return &ModManifest{}, err
+	}
+
+	for key, mod := range mods {
+		id, ok := ParseWorkshopKey(key)
+		if !ok || !mod.Enabled {
+			continue
+		}
+		item := ModManifestItem{ID: id}
+		if info, err := g.getModInfo(id); err == nil {
+			item.Name = info.Name
+		}
+		if withConfig {
+			item.ConfigurationOptions = mod.ConfigurationOptions
+		}
+		manifest.Mods = append(manifest.Mods, item)
+	}
+	sort.Slice(manifest.Mods, func(i, j int) bool {
+		return manifest.Mods[i].ID < manifest.Mods[j].ID
+	})
+
+	return manifest, nil
+}
+
+// importMods 依次下载模组并使用默认配置在指定世界启用，progress在每个模组处理完成后回调
+func (g *Game) importMods(items []WorkshopItem, worldIDs []int, overrides map[int]map[string]any, progress func(item WorkshopItem, err error)) error {
+	if err := g.eventBackup(BackupEventMod); err != nil {
+		logger.Logger.Error("导入模组前备份失败", "err", err)
+	}
+
+	worldID := 0
+	if len(worldIDs) != 0 {
+		worldID = worldIDs[0]
+	}
+
+	for _, item := range items {
+		_, ugc, err := g.getModPath(item.ID)
+		if err != nil {
+			// 未下载的模组才下载，已下载的模组通过模组更新检查来更新
+			err, _ = g.downloadMod(item.ID, item.FileURL)
+			ugc = item.FileURL == ""
+		}
+		if err == nil {
+			err = g.addModConfigToWorlds(worldID, item.ID, ugc, worldIDs, overrides[item.ID])
+		}
+		if err != nil {
+			logger.Logger.Error("导入模组失败", "err", err, "mod", item.ID)
+		}
+		progress(item, err)
+	}
+
+	return g.saveMods()
+}
@@ -0,0 +1,131 @@
+package dst
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// newWorkshopStub 模拟创意工坊GetDetails接口，details以publishedfileid为键
+func newWorkshopStub(t *testing.T, details map[string]map[string]any) (*WorkshopClient, *steamAPIStub) {
+	t.Helper()
+
+	stub := newSteamAPIStub(t, http.StatusOK, func(query url.Values) any {
+		var result []map[string]any
+		for i := 0; ; i++ {
+			id := query.Get("publishedfileids[" + strconv.Itoa(i) + "]")
+			if id == "" {
+				break
+			}
+			if detail, ok := details[id]; ok {
+				result = append(result, detail)
+			}
+		}
+		return map[string]any{
+			"response": map[string]any{"publishedfiledetails": result},
+		}
+	})
+
+	return &WorkshopClient{
+		DetailURL:  stub.URL,
+		APIKey:     func() string { return "test-key" },
+		HTTPClient: stub.Client,
+	}, stub
+}
+
+func TestWorkshopGetItems(t *testing.T) {
+	client, stub := newWorkshopStub(t, map[string]map[string]any{
+		"1001": {"publishedfileid": "1001", "title": "Mod A", "file_size": "1024", "time_updated": 1700000000},
+		"1002": {"publishedfileid": "1002", "title": "Mod B", "file_size": "2048", "time_updated": 1700000100},
+	})
+
+	items, err := client.GetItems([]int{1001, 1002, 1003}, "zh")
+	if err != nil {
+		t.Fatalf("GetItems: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[1001].Title != "Mod A" || items[1002].TimeUpdated != 1700000100 {
+		t.Errorf("unexpected items: %+v", items)
+	}
+	if _, ok := items[1003]; ok {
+		t.Errorf("missing item should not be returned")
+	}
+
+	query := stub.Queries()[0]
+	if query.Get("key") != "test-key" || query.Get("language") != "6" {
+		t.Errorf("unexpected query: %v", query)
+	}
+	if query.Get("includechildren") != "" {
+		t.Errorf("GetItems should not request children")
+	}
+}
+
+func TestWorkshopGetItemsEmpty(t *testing.T) {
+	client, stub := newWorkshopStub(t, nil)
+
+	items, err := client.GetItems(nil, "en")
+	if err != nil || len(items) != 0 {
+		t.Fatalf("expected no items and no error, got %v, %v", items, err)
+	}
+	if len(stub.Queries()) != 0 {
+		t.Errorf("empty ids should not call the API")
+	}
+}
+
+func TestWorkshopGetCollection(t *testing.T) {
+	client, stub := newWorkshopStub(t, map[string]map[string]any{
+		"2000": {
+			"publishedfileid": "2000",
+			"title":           "Collection",
+			"file_type":       workshopFileTypeCollection,
+			"children": []map[string]any{
+				{"publishedfileid": "1002", "sortorder": 2},
+				{"publishedfileid": "1001", "sortorder": 1},
+				{"publishedfileid": "1003", "sortorder": 3},
+			},
+		},
+		"1001": {"publishedfileid": "1001", "title": "Mod A"},
+		"1002": {"publishedfileid": "1002", "title": "Mod B"},
+	})
+
+	collection, err := client.GetCollection(2000, "en")
+	if err != nil {
+		t.Fatalf("GetCollection: %v", err)
+	}
+	if collection.Title != "Collection" || len(collection.Children) != 3 {
+		t.Fatalf("unexpected collection: %+v", collection)
+	}
+	// 按sortorder排序，没有详情的模组保留ID
+	expected := []WorkshopItem{{ID: 1001, Title: "Mod A"}, {ID: 1002, Title: "Mod B"}, {ID: 1003}}
+	for i, item := range collection.Children {
+		if item.ID != expected[i].ID || item.Title != expected[i].Title {
+			t.Errorf("child %d: expected %+v, got %+v", i, expected[i], item)
+		}
+	}
+
+	if stub.Queries()[0].Get("includechildren") != "true" {
+		t.Errorf("collection request should include children")
+	}
+}
+
+func TestWorkshopGetCollectionNotCollection(t *testing.T) {
+	client, _ := newWorkshopStub(t, map[string]map[string]any{
+		"1001": {"publishedfileid": "1001", "title": "Mod A"},
+	})
+
+	if _, err := client.GetCollection(1001, "en"); err == nil {
+		t.Errorf("expected error for a non-collection item")
+	}
+}
+
+func TestWorkshopHTTPError(t *testing.T) {
+	stub := newSteamAPIStub(t, http.StatusForbidden, nil)
+
+	client := &WorkshopClient{DetailURL: stub.URL, HTTPClient: stub.Client}
+	if _, err := client.GetItems([]int{1001}, "en"); err == nil {
+		t.Errorf("expected error for non-200 response")
+	}
+}
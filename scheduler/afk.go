@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"dst-management-platform-api/database/db"
+	"dst-management-platform-api/database/models"
+	"dst-management-platform-api/dst"
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+	"math"
+	"strings"
+)
+
+// afkMoveDistance 坐标变化超过该距离视为移动
+const afkMoveDistance = 1
+
+// updatePlayersActivity 采样在线玩家的坐标，返回挂机的玩家，players为GetOnlinePlayerList的结果
+func updatePlayersActivity(roomID int, game *dst.Game, setting dst.AfkSetting, players []string) map[string]bool {
+	coordinates := game.PlayerCoordinates()
+	now := utils.GetTimestamp()
+	window := int64(setting.Window) * 60 * 1000
+
+	db.PlayersActivityMutex.Lock()
+	defer db.PlayersActivityMutex.Unlock()
+
+	lastActivity := db.PlayersActivity[roomID]
+	activity := make(map[string]db.PlayerActivity)
+	afkPlayers := make(map[string]bool)
+
+	for _, player := range players {
+		uid := strings.Split(player, "<-@dmp@->")[0]
+		state, ok := lastActivity[uid]
+		coordinate, sampled := coordinates[uid]
+		if !ok {
+			state.LastMoved = now
+		}
+		// 没有采样到坐标时保持上一次的状态
+		if sampled {
+			if ok && math.Hypot(coordinate.X-state.X, coordinate.Z-state.Z) > afkMoveDistance {
+				state.LastMoved = now
+				state.WarnedAt = 0
+			}
+			state.X = coordinate.X
+			state.Z = coordinate.Z
+		}
+		state.Afk = now-state.LastMoved >= window
+		activity[uid] = state
+		if state.Afk {
+			afkPlayers[uid] = true
+		}
+	}
+
+	// 离线玩家的记录不再保留
+	db.PlayersActivity[roomID] = activity
+
+	return afkPlayers
+}
+
+// kickAfkPlayers 服务器接近满员时先提示挂机玩家，提示后仍未移动则踢出
+func kickAfkPlayers(room *models.Room, game *dst.Game, setting dst.AfkSetting, onlineCount int) {
+	if !setting.KickEnable || onlineCount < room.MaxPlayer-setting.KickFreeSlots {
+		return
+	}
+
+	now := utils.GetTimestamp()
+
+	db.PlayersActivityMutex.Lock()
+	defer db.PlayersActivityMutex.Unlock()
+
+	for uid, state := range db.PlayersActivity[room.ID] {
+		if !state.Afk {
+			continue
+		}
+
+		if state.WarnedAt == 0 && setting.WarnMessage != "" {
+			err := game.PlayerAction(dst.PlayerAction{Action: dst.PlayerActionMessage, UID: uid, Message: setting.WarnMessage})
+			if err != nil {
+				logger.Logger.Warn("提示挂机玩家失败", "err", err, "uid", uid)
+			}
+			state.WarnedAt = now
+			db.PlayersActivity[room.ID][uid] = state
+			continue
+		}
+
+		if now-state.WarnedAt < int64(setting.WarnGrace)*1000 {
+			continue
+		}
+
+		if err := game.PlayerAction(dst.PlayerAction{Action: dst.PlayerActionKick, UID: uid}); err != nil {
+			logger.Logger.Warn("踢出挂机玩家失败", "err", err, "uid", uid)
+			continue
+		}
+		logger.Logger.Info("服务器接近满员，踢出挂机玩家", "room", room.ID, "uid", uid)
+		delete(db.PlayersActivity[room.ID], uid)
+	}
+}
+
+// clearPlayersActivity 关闭挂机检测后清除房间的玩家活动记录
+func clearPlayersActivity(roomID int) {
+	db.PlayersActivityMutex.Lock()
+	delete(db.PlayersActivity, roomID)
+	db.PlayersActivityMutex.Unlock()
+}
@@ -0,0 +1,187 @@
+package scheduler
+
+import (
+	"dst-management-platform-api/database/models"
+	"dst-management-platform-api/dst"
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+	"fmt"
+	"slices"
+)
+
+// BanPlayers 封禁玩家，已有的封禁会被新的封禁替换，duration为0时永久封禁，单位秒
+func BanPlayers(roomID int, uids []string, reason, issuedBy string, duration int64) ([]models.PlayerBan, error) {
+	for _, uid := range uids {
+		if !dst.ValidUID(uid) {
+			return nil, fmt.Errorf("UID格式错误: %s", uid)
+		}
+	}
+
+	room, worlds, roomSetting, err := fetchGameInfo(roomID)
+	if err != nil {
+		return nil, err
+	}
+	game := dst.NewGameController(room, worlds, roomSetting, "zh")
+
+	now := utils.GetTimestamp()
+	var bans []models.PlayerBan
+	for _, uid := range uids {
+		if err = liftActiveBans(roomID, uid, issuedBy, now); err != nil {
+			return bans, err
+		}
+
+		ban := models.PlayerBan{
+			RoomID:    roomID,
+			UID:       uid,
+			Reason:    reason,
+			IssuedBy:  issuedBy,
+			CreatedAt: now,
+			Active:    true,
+		}
+		if duration > 0 {
+			ban.ExpiresAt = now + duration*1000
+		}
+		if player, err := DBHandler.playerDao.GetPlayerByUID(uid); err == nil {
+			ban.Nickname = player.Nickname
+		}
+		if err = DBHandler.playerBanDao.Create(&ban); err != nil {
+			return bans, err
+		}
+		bans = append(bans, ban)
+	}
+
+	if err = applyPlayerLists(roomID, game); err != nil {
+		return bans, err
+	}
+
+	for _, uid := range uids {
+		if err = game.BanPlayer(uid, duration); err != nil {
+			logger.Logger.Info("游戏内封禁玩家失败，将在下次启动时生效", "err", err, "uid", uid)
+		}
+	}
+
+	return bans, nil
+}
+
+// LiftPlayerBans 解封玩家
+func LiftPlayerBans(roomID int, uids []string, liftedBy string) error {
+	room, worlds, roomSetting, err := fetchGameInfo(roomID)
+	if err != nil {
+		return err
+	}
+	game := dst.NewGameController(room, worlds, roomSetting, "zh")
+
+	now := utils.GetTimestamp()
+	for _, uid := range uids {
+		if err = liftActiveBans(roomID, uid, liftedBy, now); err != nil {
+			return err
+		}
+	}
+
+	// 游戏内的封禁在内存中，重启世界后才会完全解除
+	return applyPlayerLists(roomID, game)
+}
+
+func liftActiveBans(roomID int, uid, liftedBy string, now int64) error {
+	bans, err := DBHandler.playerBanDao.GetActiveBan(roomID, uid)
+	if err != nil {
+		return err
+	}
+
+	var ids []int
+	for _, ban := range *bans {
+		ids = append(ids, ban.ID)
+	}
+
+	return DBHandler.playerBanDao.LiftBans(ids, liftedBy, now)
+}
+
+// BanExpire 自动解除到期的封禁
+func BanExpire() {
+	now := utils.GetTimestamp()
+	bans, err := DBHandler.playerBanDao.GetExpiredBans(now)
+	if err != nil {
+		logger.Logger.Error("查询到期封禁失败", "err", err)
+		return
+	}
+	if len(*bans) == 0 {
+		return
+	}
+
+	var ids []int
+	roomIDs := make(map[int]bool)
+	for _, ban := range *bans {
+		ids = append(ids, ban.ID)
+		roomIDs[ban.RoomID] = true
+	}
+	if err = DBHandler.playerBanDao.LiftBans(ids, "system", now); err != nil {
+		logger.Logger.Error("解除到期封禁失败", "err", err)
+		return
+	}
+
+	for roomID := range roomIDs {
+		room, worlds, roomSetting, err := fetchGameInfo(roomID)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			continue
+		}
+		game := dst.NewGameController(room, worlds, roomSetting, "zh")
+		if err = applyPlayerLists(roomID, game); err != nil {
+			logger.Logger.Error("更新黑名单失败", "err", err, "room", roomID)
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("已自动解除%d个到期封禁", len(ids)))
+}
+
+// ImportBlocklist 将上传存档等来源的黑名单导入为房间的永久封禁，需要在同步平台级名单前调用
+func ImportBlocklist(roomID int, uids []string) error {
+	globalLists, err := globalPlayerListsForRoom(roomID)
+	if err != nil {
+		return err
+	}
+	importBlocklist(roomID, uids, globalLists["blocklist"])
+
+	return nil
+}
+
+// CopyRoomBans 将源房间生效中的封禁复制到新房间，用于复制房间
+func CopyRoomBans(fromRoomID, toRoomID int) error {
+	bans, err := DBHandler.playerBanDao.GetBansByRoomID(fromRoomID, false)
+	if err != nil {
+		return err
+	}
+
+	for _, ban := range *bans {
+		ban.ID = 0
+		ban.RoomID = toRoomID
+		if err = DBHandler.playerBanDao.Create(&ban); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importBlocklist 将没有封禁记录的UID导入为永久封禁，平台级黑名单中的UID不导入
+func importBlocklist(roomID int, uids, globalBlocklist []string) {
+	now := utils.GetTimestamp()
+	for _, uid := range uids {
+		if !dst.ValidUID(uid) || slices.Contains(globalBlocklist, uid) {
+			continue
+		}
+		count, err := DBHandler.playerBanDao.CountBansByRoomAndUID(roomID, uid)
+		if err != nil || count != 0 {
+			continue
+		}
+		ban := models.PlayerBan{
+			RoomID:    roomID,
+			UID:       uid,
+			CreatedAt: now,
+			Active:    true,
+		}
+		if err = DBHandler.playerBanDao.Create(&ban); err != nil {
+			logger.Logger.Error("导入黑名单失败", "err", err, "room", roomID)
+		}
+	}
+}
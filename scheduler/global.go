@@ -36,7 +36,25 @@ func OnlinePlayerGet(interval int, uidMapEnable bool) {
 			if game.WorldUpStatus(world.ID) {
 				players, err := game.GetOnlinePlayerList(world.ID)
 				if err == nil {
+					// 挂机检测，挂机时间不计入在线时长
+					afkPlayers := map[string]bool{}
+					afkSetting, afkErr := dst.ParseAfkSetting(roomSetting.AfkSetting)
+					if roomSetting.AfkEnable && afkErr == nil {
+						afkPlayers = updatePlayersActivity(rbs.RoomID, game, afkSetting, players)
+					} else {
+						clearPlayersActivity(rbs.RoomID)
+					}
+					// 预留位，记录玩家加入顺序
+					var newPlayers []string
+					reservedSlotSetting, reservedSlotErr := dst.ParseReservedSlotSetting(roomSetting.ReservedSlotSetting)
+					if roomSetting.ReservedSlotEnable && reservedSlotErr == nil {
+						newPlayers = updatePlayersJoinedAt(rbs.RoomID, players)
+					} else {
+						clearPlayersJoinedAt(rbs.RoomID)
+					}
+
 					var ps []db.PlayerInfo
+					playTimes := make(map[string]int64)
 					for _, player := range players {
 						var playerInfo db.PlayerInfo // 单个玩家
 						uidNickName := strings.Split(player, "<-@dmp@->")
@@ -46,12 +64,17 @@ func OnlinePlayerGet(interval int, uidMapEnable bool) {
 						ps = append(ps, playerInfo)
 
 						// 玩家在线时长统计
+						playTime := interval
+						if afkPlayers[playerInfo.UID] {
+							playTime = 0
+						}
 						db.PlayersOnlineTimeMutex.Lock()
 						if db.PlayersOnlineTime[rbs.RoomID] == nil {
 							db.PlayersOnlineTime[rbs.RoomID] = make(map[string]int)
 						}
-						db.PlayersOnlineTime[rbs.RoomID][playerInfo.Nickname] = db.PlayersOnlineTime[rbs.RoomID][playerInfo.Nickname] + interval
+						db.PlayersOnlineTime[rbs.RoomID][playerInfo.Nickname] = db.PlayersOnlineTime[rbs.RoomID][playerInfo.Nickname] + playTime
 						db.PlayersOnlineTimeMutex.Unlock()
+						playTimes[playerInfo.UID] = int64(playTime)
 
 						// 更新uidMap
 						if uidMapEnable {
@@ -65,10 +88,22 @@ func OnlinePlayerGet(interval int, uidMapEnable bool) {
 								logger.Logger.Error("更新UID MAP失败", "err", err)
 							}
 						}
+						// 玩家目录，保留历史昵称和各房间的出现记录，不受UID MAP开关影响
+						err = DBHandler.playerDao.RecordPresence(playerInfo.UID, playerInfo.Nickname, rbs.RoomID, utils.GetTimestamp(), int64(playTime))
+						if err != nil {
+							logger.Logger.Error("更新玩家目录失败", "err", err)
+						}
 					}
 					if ps == nil {
 						ps = []db.PlayerInfo{}
 					}
+					recordOnlineActivity(rbs.RoomID, playTimes)
+					if roomSetting.AfkEnable && afkErr == nil {
+						kickAfkPlayers(room, game, afkSetting, len(ps))
+					}
+					if roomSetting.ReservedSlotEnable && reservedSlotErr == nil {
+						kickForVip(room, game, reservedSlotSetting, newPlayers, len(ps))
+					}
 					Players.PlayerInfo = ps
 					Players.Timestamp = utils.GetTimestamp()
 
@@ -125,6 +160,8 @@ func GameUpdate(enable bool, restart bool) {
 		logger.Logger.Info("检测到游戏需要更新")
 		logger.Logger.Info("开始执行游戏更新")
 
+		BackupBeforeUpdate()
+
 		db.DstUpdating = true
 
 		updateCmd := fmt.Sprintf("cd ~/steamcmd && ./steamcmd.sh +login anonymous +force_install_dir ~/dst +app_update 343050 validate +quit")
@@ -172,6 +209,118 @@ func GameUpdate(enable bool, restart bool) {
 	}
 }
 
+// BackupBeforeUpdate 游戏更新前，备份所有激活的房间
+func BackupBeforeUpdate() {
+	roomsBasic, err := DBHandler.roomDao.GetRoomBasic()
+	if err != nil {
+		logger.Logger.Error("查询数据库失败，跳过更新前备份", "err", err)
+		return
+	}
+
+	for _, rbs := range *roomsBasic {
+		if !rbs.Status {
+			continue
+		}
+		room, worlds, roomSetting, err := fetchGameInfo(rbs.RoomID)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败，跳过更新前备份", "err", err)
+			continue
+		}
+		game := dst.NewGameController(room, worlds, roomSetting, "zh")
+		err = game.EventBackup(dst.BackupEventUpdate)
+		if err != nil {
+			logger.Logger.Error("更新前备份失败", "err", err, "room", rbs.RoomID)
+		}
+	}
+}
+
+// BackupEventCheck 检查所有激活房间的天数和季节，触发事件备份
+func BackupEventCheck() {
+	roomsBasic, err := DBHandler.roomDao.GetRoomBasic()
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		return
+	}
+
+	for _, rbs := range *roomsBasic {
+		if !rbs.Status {
+			continue
+		}
+		room, worlds, roomSetting, err := fetchGameInfo(rbs.RoomID)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			continue
+		}
+		game := dst.NewGameController(room, worlds, roomSetting, "zh")
+		err = game.SessionEventBackup()
+		if err != nil {
+			logger.Logger.Error("事件备份失败", "err", err, "room", rbs.RoomID)
+		}
+	}
+}
+
+// CheckModUpdates 检查房间的模组更新，并记录到数据库
+func CheckModUpdates(game *dst.Game, roomID int) (*[]models.ModVersion, error) {
+	downloadedMods, err := game.CheckModUpdates()
+	if err != nil {
+		return &[]models.ModVersion{}, err
+	}
+
+	checkedAt := utils.GetTimestamp()
+	var modVersions []models.ModVersion
+	for _, mod := range downloadedMods {
+		if mod.ID == 0 {
+			continue
+		}
+		modVersions = append(modVersions, models.ModVersion{
+			RoomID:            roomID,
+			ModID:             mod.ID,
+			Name:              mod.Name,
+			Version:           mod.Version,
+			FileURL:           mod.FileURL,
+			LocalTimeUpdated:  mod.LocalTimeUpdated,
+			ServerTimeUpdated: mod.ServerTimeUpdated,
+			Outdated:          mod.Outdated,
+			CheckedAt:         checkedAt,
+		})
+	}
+
+	err = DBHandler.modVersionDao.UpdateModVersions(roomID, &modVersions)
+
+	return &modVersions, err
+}
+
+// ModUpdateCheck 检查所有激活房间的模组更新
+func ModUpdateCheck() {
+	roomsBasic, err := DBHandler.roomDao.GetRoomBasic()
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		return
+	}
+
+	for _, rbs := range *roomsBasic {
+		if !rbs.Status {
+			continue
+		}
+		room, worlds, roomSetting, err := fetchGameInfo(rbs.RoomID)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			continue
+		}
+		game := dst.NewGameController(room, worlds, roomSetting, "zh")
+		modVersions, err := CheckModUpdates(game, rbs.RoomID)
+		if err != nil {
+			logger.Logger.Error("检查模组更新失败", "err", err, "room", rbs.RoomID)
+			continue
+		}
+		for _, modVersion := range *modVersions {
+			if modVersion.Outdated {
+				logger.Logger.Info("发现模组更新", "room", rbs.RoomID, "mod", modVersion.ModID, "name", modVersion.Name)
+			}
+		}
+	}
+}
+
 func InternetIPUpdate() {
 	var (
 		internetIp string
@@ -190,6 +339,17 @@ func InternetIPUpdate() {
 	db.InternetIP = internetIp
 }
 
+func ModStorePrune() {
+	freed, err := dst.PruneModStore()
+	if err != nil {
+		logger.Logger.Error("清理共享模组失败", "err", err)
+		return
+	}
+	if freed != 0 {
+		logger.Logger.Info(fmt.Sprintf("清理共享模组成功，共释放%d字节", freed))
+	}
+}
+
 func ModDownloadClean() {
 	if atomic.LoadInt32(&db.ModDownloadExecuting) == 0 {
 		err := utils.RemoveDir(fmt.Sprintf("%s/mods/ugc", utils.DmpFiles))
@@ -198,3 +358,15 @@ func ModDownloadClean() {
 		}
 	}
 }
+
+// importUidMap 将旧版本uid_map中的玩家导入玩家目录
+func importUidMap() {
+	count, err := DBHandler.playerDao.ImportUidMap(utils.GetTimestamp())
+	if err != nil {
+		logger.Logger.Error("导入玩家目录失败", "err", err)
+		return
+	}
+	if count != 0 {
+		logger.Logger.Info(fmt.Sprintf("已将%d个玩家导入玩家目录", count))
+	}
+}
@@ -11,8 +11,11 @@ import (
 )
 
 // Start 开启定时任务
-func Start(roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO, globalSettingDao *dao.GlobalSettingDAO, uidMapDao *dao.UidMapDAO) {
-	DBHandler = newDBHandler(roomDao, worldDao, roomSettingDao, globalSettingDao, uidMapDao)
+func Start(roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO, globalSettingDao *dao.GlobalSettingDAO, uidMapDao *dao.UidMapDAO, backupPinDao *dao.BackupPinDAO, modVersionDao *dao.ModVersionDAO, modDownloadJobDao *dao.ModDownloadJobDAO, playerDao *dao.PlayerDAO, playerBanDao *dao.PlayerBanDAO, globalPlayerListDao *dao.GlobalPlayerListDAO, playerActivityDao *dao.PlayerActivityDAO) {
+	DBHandler = newDBHandler(roomDao, worldDao, roomSettingDao, globalSettingDao, uidMapDao, backupPinDao, modVersionDao, modDownloadJobDao, playerDao, playerBanDao, globalPlayerListDao, playerActivityDao)
+	startModDownloadQueue()
+	importUidMap()
+	ApplyGlobalPlayerLists(nil, nil)
 	initJobs()
 	registerJobs()
 	go Scheduler.StartAsync()
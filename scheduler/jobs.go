@@ -60,6 +60,66 @@ func initJobs() {
 		DayAt:    "",
 	})
 
+	// 天数、季节事件备份
+	Jobs = append(Jobs, JobConfig{
+		Name:     "backupEventCheck",
+		Func:     BackupEventCheck,
+		Args:     nil,
+		TimeType: MinuteType,
+		Interval: 1,
+		DayAt:    "",
+	})
+
+	// 到期封禁解除
+	Jobs = append(Jobs, JobConfig{
+		Name:     "banExpire",
+		Func:     BanExpire,
+		Args:     nil,
+		TimeType: MinuteType,
+		Interval: 1,
+		DayAt:    "",
+	})
+
+	// 玩家存活天数、死亡次数采样
+	Jobs = append(Jobs, JobConfig{
+		Name:     "playerStatsGet",
+		Func:     PlayerStatsGet,
+		Args:     nil,
+		TimeType: MinuteType,
+		Interval: 1,
+		DayAt:    "",
+	})
+
+	// 每周活跃报告
+	Jobs = append(Jobs, JobConfig{
+		Name:     "weeklyActivityReport",
+		Func:     WeeklyActivityReport,
+		Args:     nil,
+		TimeType: DayType,
+		Interval: 0,
+		DayAt:    "00:10:00",
+	})
+
+	// Steam玩家资料
+	Jobs = append(Jobs, JobConfig{
+		Name:     "steamProfileGet",
+		Func:     SteamProfileGet,
+		Args:     []any{globalSetting.SteamProfileEnable},
+		TimeType: HourType,
+		Interval: 1,
+		DayAt:    "",
+	})
+
+	// 模组更新检查
+	Jobs = append(Jobs, JobConfig{
+		Name:     "modUpdateCheck",
+		Func:     ModUpdateCheck,
+		Args:     nil,
+		TimeType: HourType,
+		Interval: 1,
+		DayAt:    "",
+	})
+
 	// 清理临时模组
 	Jobs = append(Jobs, JobConfig{
 		Name:     "ModDownloadClean",
@@ -70,6 +130,16 @@ func initJobs() {
 		DayAt:    "",
 	})
 
+	// 清理未引用的共享模组
+	Jobs = append(Jobs, JobConfig{
+		Name:     "modStorePrune",
+		Func:     ModStorePrune,
+		Args:     nil,
+		TimeType: HourType,
+		Interval: 6,
+		DayAt:    "",
+	})
+
 	// 房间定时任务
 	roomBasic, err := DBHandler.roomDao.GetRoomBasic()
 	if err != nil {
@@ -116,7 +186,7 @@ func initJobs() {
 			Jobs = append(Jobs, JobConfig{
 				Name:     fmt.Sprintf("%d-BackupClean", room.ID),
 				Func:     BackupClean,
-				Args:     []any{room.ID, roomSetting.BackupCleanSetting},
+				Args:     []any{room.ID, roomSetting.BackupCleanSetting, roomSetting.BackupRetentionSetting},
 				TimeType: DayType,
 				Interval: 0,
 				DayAt:    "05:16:27",
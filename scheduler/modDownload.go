@@ -0,0 +1,304 @@
+package scheduler
+
+import (
+	"context"
+	"dst-management-platform-api/database/models"
+	"dst-management-platform-api/dst"
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// modDownloadMaxAttempts 模组下载最大尝试次数
+	modDownloadMaxAttempts = 3
+	// modDownloadRetryDelay 第n次失败后等待n倍的时间再重试
+	modDownloadRetryDelay = 30 * time.Second
+	// modDownloadSaveInterval 下载进度写入数据库的最小间隔
+	modDownloadSaveInterval = 2 * time.Second
+)
+
+var ErrModDownloadFinished = errors.New("模组下载任务已结束")
+
+// modDownloadQueue 模组下载队列，同一时间只下载一个模组，避免steamcmd和acf文件冲突
+type modDownloadQueue struct {
+	mutex       sync.Mutex
+	wake        chan struct{}
+	cancels     map[int]context.CancelFunc
+	subscribers map[chan models.ModDownloadJob]struct{}
+}
+
+var modQueue = &modDownloadQueue{
+	wake:        make(chan struct{}, 1),
+	cancels:     make(map[int]context.CancelFunc),
+	subscribers: make(map[chan models.ModDownloadJob]struct{}),
+}
+
+func startModDownloadQueue() {
+	err := DBHandler.modDownloadJobDao.ResetRunningJobs()
+	if err != nil {
+		logger.Logger.Error("重置模组下载任务失败", "err", err)
+	}
+	dst.ModDownloader = downloadModQueued
+	go modQueue.run()
+}
+
+// EnqueueModDownload 添加模组下载任务
+func EnqueueModDownload(roomID, modID int, name, fileURL string) (*models.ModDownloadJob, error) {
+	now := utils.GetTimestamp()
+	job := &models.ModDownloadJob{
+		RoomID:      roomID,
+		ModID:       modID,
+		Name:        name,
+		FileURL:     fileURL,
+		Status:      models.ModDownloadQueued,
+		MaxAttempts: modDownloadMaxAttempts,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := DBHandler.modDownloadJobDao.Create(job); err != nil {
+		return job, err
+	}
+
+	modQueue.publish(*job)
+	modQueue.notify()
+
+	return job, nil
+}
+
+// CancelModDownload 取消模组下载任务，正在下载的任务由下载协程更新状态
+func CancelModDownload(jobID int) (*models.ModDownloadJob, error) {
+	// 只有仍在排队的任务才会被修改，避免与下载协程开始下载同时发生
+	canceled, err := DBHandler.modDownloadJobDao.UpdateStatusIf(jobID, models.ModDownloadQueued, models.ModDownloadCanceled, utils.GetTimestamp())
+	if err != nil {
+		return &models.ModDownloadJob{}, err
+	}
+
+	job, err := DBHandler.modDownloadJobDao.GetJobByID(jobID)
+	if err != nil {
+		return job, err
+	}
+	if canceled {
+		modQueue.publish(*job)
+		return job, nil
+	}
+
+	if job.Status != models.ModDownloadRunning {
+		return job, ErrModDownloadFinished
+	}
+
+	// 下载协程在开始下载前注册取消函数
+	modQueue.mutex.Lock()
+	cancel, ok := modQueue.cancels[jobID]
+	modQueue.mutex.Unlock()
+	if ok {
+		cancel()
+	}
+
+	return job, nil
+}
+
+// modDownloadFinished 任务是否已结束，等待重试的任务仍为排队中
+func modDownloadFinished(status string) bool {
+	return status == models.ModDownloadSucceeded || status == models.ModDownloadFailed || status == models.ModDownloadCanceled
+}
+
+// WaitModDownload 等待模组下载任务结束，返回任务的最终状态
+func WaitModDownload(ctx context.Context, jobID int) (*models.ModDownloadJob, error) {
+	ch, unsubscribe := SubscribeModDownload()
+	defer unsubscribe()
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		// 先订阅再查询，避免错过查询前结束的任务；推送可能被丢弃，定时从数据库确认
+		job, err := DBHandler.modDownloadJobDao.GetJobByID(jobID)
+		if err != nil {
+			return job, err
+		}
+		if modDownloadFinished(job.Status) {
+			return job, nil
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return job, ctx.Err()
+			case update := <-ch:
+				if update.ID == jobID && modDownloadFinished(update.Status) {
+					return &update, nil
+				}
+			case <-ticker.C:
+				break wait
+			}
+		}
+	}
+}
+
+// downloadModQueued 将模组加入下载队列并等待完成，返回模组大小
+func downloadModQueued(roomID, modID int, name, fileURL string) (int64, error) {
+	job, err := EnqueueModDownload(roomID, modID, name, fileURL)
+	if err != nil {
+		return 0, err
+	}
+	job, err = WaitModDownload(context.Background(), job.ID)
+	if err != nil {
+		return 0, err
+	}
+	if job.Status != models.ModDownloadSucceeded {
+		if job.Error != "" {
+			return 0, fmt.Errorf("模组下载失败: %s", job.Error)
+		}
+		return 0, fmt.Errorf("模组下载任务未完成，状态: %s", job.Status)
+	}
+
+	return job.TotalBytes, nil
+}
+
+// SubscribeModDownload 订阅模组下载任务的状态变化，返回的函数用于取消订阅
+func SubscribeModDownload() (<-chan models.ModDownloadJob, func()) {
+	ch := make(chan models.ModDownloadJob, 64)
+
+	modQueue.mutex.Lock()
+	modQueue.subscribers[ch] = struct{}{}
+	modQueue.mutex.Unlock()
+
+	return ch, func() {
+		modQueue.mutex.Lock()
+		delete(modQueue.subscribers, ch)
+		modQueue.mutex.Unlock()
+	}
+}
+
+func (q *modDownloadQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// publish 推送任务状态，订阅者处理不过来时丢弃
+func (q *modDownloadQueue) publish(job models.ModDownloadJob) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for ch := range q.subscribers {
+		select {
+		case ch <- job:
+		default:
+		}
+	}
+}
+
+func (q *modDownloadQueue) run() {
+	for {
+		job, err := DBHandler.modDownloadJobDao.GetNextQueuedJob(utils.GetTimestamp())
+		if err != nil {
+			logger.Logger.Error("查询模组下载任务失败", "err", err)
+		}
+		if job == nil {
+			select {
+			case <-q.wake:
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		q.execute(job)
+	}
+}
+
+func (q *modDownloadQueue) save(job *models.ModDownloadJob) {
+	job.UpdatedAt = utils.GetTimestamp()
+	if err := DBHandler.modDownloadJobDao.Update(job); err != nil {
+		logger.Logger.Error("更新模组下载任务失败", "err", err)
+	}
+	q.publish(*job)
+}
+
+func (q *modDownloadQueue) execute(job *models.ModDownloadJob) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q.mutex.Lock()
+	q.cancels[job.ID] = cancel
+	q.mutex.Unlock()
+	defer func() {
+		q.mutex.Lock()
+		delete(q.cancels, job.ID)
+		q.mutex.Unlock()
+	}()
+
+	// 任务可能在查询后被取消，只有成功从排队改为下载中才继续
+	claimed, err := DBHandler.modDownloadJobDao.UpdateStatusIf(job.ID, models.ModDownloadQueued, models.ModDownloadRunning, utils.GetTimestamp())
+	if err != nil {
+		logger.Logger.Error("更新模组下载任务失败", "err", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	job.Status = models.ModDownloadRunning
+	job.Attempts++
+	job.Progress = 0
+	job.Bytes = 0
+	job.Error = ""
+	q.save(job)
+	logger.Logger.Info("开始下载模组", "job", job.ID, "mod", job.ModID, "attempt", job.Attempts)
+
+	room, worlds, roomSetting, err := fetchGameInfo(job.RoomID)
+	if err != nil {
+		job.Status = models.ModDownloadFailed
+		job.Error = err.Error()
+		q.save(job)
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, "zh")
+
+	var lastSaved int64
+	err, modSize := game.DownloadModContext(ctx, job.ModID, job.FileURL, func(bytes, total int64, percent float64) {
+		job.Bytes = bytes
+		job.TotalBytes = total
+		job.Progress = percent
+		// 进度变化频繁，推送给订阅者，数据库限制写入频率
+		now := utils.GetTimestamp()
+		if now-lastSaved >= modDownloadSaveInterval.Milliseconds() {
+			lastSaved = now
+			q.save(job)
+		} else {
+			q.publish(*job)
+		}
+	})
+
+	switch {
+	case ctx.Err() != nil:
+		job.Status = models.ModDownloadCanceled
+		logger.Logger.Info("模组下载已取消", "job", job.ID, "mod", job.ModID)
+	case err != nil:
+		job.Error = err.Error()
+		if job.Attempts < job.MaxAttempts {
+			job.Status = models.ModDownloadQueued
+			job.NextRunAt = utils.GetTimestamp() + int64(job.Attempts)*modDownloadRetryDelay.Milliseconds()
+			logger.Logger.Warn("模组下载失败，等待重试", "job", job.ID, "mod", job.ModID, "err", err)
+		} else {
+			job.Status = models.ModDownloadFailed
+			logger.Logger.Error("模组下载失败", "job", job.ID, "mod", job.ModID, "err", err)
+		}
+	default:
+		job.Status = models.ModDownloadSucceeded
+		job.Progress = 100
+		if job.TotalBytes == 0 {
+			job.TotalBytes = modSize
+		}
+		job.Bytes = job.TotalBytes
+		logger.Logger.Info("模组下载成功", "job", job.ID, "mod", job.ModID)
+	}
+	q.save(job)
+}
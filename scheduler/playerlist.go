@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"dst-management-platform-api/dst"
+	"dst-management-platform-api/logger"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// globalPlayerListsForRoom 获取对房间生效的平台级名单
+func globalPlayerListsForRoom(roomID int) (map[string][]string, error) {
+	lists, err := DBHandler.globalPlayerListDao.GetGlobalPlayerLists("")
+	if err != nil {
+		return nil, err
+	}
+
+	roomIDStr := strconv.Itoa(roomID)
+	result := make(map[string][]string)
+	for _, list := range *lists {
+		if list.Rooms != "" && !slices.Contains(strings.Split(list.Rooms, ","), roomIDStr) {
+			continue
+		}
+		if slices.Contains(strings.Split(list.ExcludedRooms, ","), roomIDStr) {
+			continue
+		}
+		result[list.ListType] = append(result[list.ListType], list.UID)
+	}
+
+	return result, nil
+}
+
+// applyPlayerLists 生成房间名单，黑名单为生效中的封禁加平台级黑名单，管理员名单和白名单合并平台级名单
+func applyPlayerLists(roomID int, game *dst.Game) error {
+	globalLists, err := globalPlayerListsForRoom(roomID)
+	if err != nil {
+		return err
+	}
+
+	// 重新生成blocklist.txt前先导入文件中没有封禁记录的UID，避免新建、恢复或手动修改的房间丢失黑名单
+	importBlocklist(roomID, game.LocalBlocklist(), globalLists["blocklist"])
+
+	bans, err := DBHandler.playerBanDao.GetBansByRoomID(roomID, false)
+	if err != nil {
+		return err
+	}
+
+	blocklist := []string{}
+	for _, ban := range *bans {
+		blocklist = append(blocklist, ban.UID)
+	}
+
+	if err = game.SetBlocklist(blocklist, globalLists["blocklist"]); err != nil {
+		return err
+	}
+
+	return game.ApplyGlobalPlayerLists(globalLists["adminlist"], globalLists["whitelist"])
+}
+
+// ApplyGlobalPlayerLists 将平台级名单同步到指定房间，roomIDs为空时同步所有房间，banUIDs会在运行中的世界立即封禁，返回同步失败的房间
+func ApplyGlobalPlayerLists(roomIDs []int, banUIDs []string) []int {
+	if len(roomIDs) == 0 {
+		roomsBasic, err := DBHandler.roomDao.GetRoomBasic()
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			return roomIDs
+		}
+		for _, rbs := range *roomsBasic {
+			roomIDs = append(roomIDs, rbs.RoomID)
+		}
+	}
+
+	failed := []int{}
+	for _, roomID := range roomIDs {
+		room, worlds, roomSetting, err := fetchGameInfo(roomID)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			failed = append(failed, roomID)
+			continue
+		}
+		game := dst.NewGameController(room, worlds, roomSetting, "zh")
+		if err = applyPlayerLists(roomID, game); err != nil {
+			logger.Logger.Error("同步平台级名单失败", "err", err, "room", roomID)
+			failed = append(failed, roomID)
+			continue
+		}
+		if len(banUIDs) == 0 {
+			continue
+		}
+		globalLists, err := globalPlayerListsForRoom(roomID)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			continue
+		}
+		for _, uid := range banUIDs {
+			if !slices.Contains(globalLists["blocklist"], uid) {
+				continue
+			}
+			if err = game.BanPlayer(uid, 0); err != nil {
+				logger.Logger.Info("游戏内封禁玩家失败，将在下次启动时生效", "err", err, "uid", uid, "room", roomID)
+			}
+		}
+	}
+
+	return failed
+}
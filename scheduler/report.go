@@ -0,0 +1,195 @@
+package scheduler
+
+import (
+	"dst-management-platform-api/database/dao"
+	"dst-management-platform-api/database/models"
+	"dst-management-platform-api/dst"
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// activityRetentionDays 活跃记录保留天数
+const activityRetentionDays = 400
+
+// ActivityReportData 活跃报告内容
+type ActivityReportData struct {
+	RoomID        int   `json:"roomID"`
+	PeriodStart   int64 `json:"periodStart"`
+	PeriodEnd     int64 `json:"periodEnd"`
+	PeakPlayers   int   `json:"peakPlayers"`
+	PeakAt        int64 `json:"peakAt"`
+	UniquePlayers int   `json:"uniquePlayers"`
+	NewPlayers    int   `json:"newPlayers"`
+	// 上一个周期的新玩家中，本周期仍然活跃的比例
+	PreviousNewPlayers int                         `json:"previousNewPlayers"`
+	RetainedPlayers    int                         `json:"retainedPlayers"`
+	Retention          float64                     `json:"retention"`
+	TotalPlayTime      int64                       `json:"totalPlayTime"`
+	TotalDeaths        int                         `json:"totalDeaths"`
+	ActiveHours        [24]int64                   `json:"activeHours"` // 按本地时间每个小时的总在线时长
+	Players            []dao.PlayerActivitySummary `json:"players"`
+	NewPlayerUIDs      []string                    `json:"newPlayerUIDs"`
+}
+
+// currentHour 当前整点时间戳，单位毫秒
+func currentHour() int64 {
+	return time.Now().Truncate(time.Hour).UnixMilli()
+}
+
+// recordOnlineActivity 记录玩家在线时长和房间在线人数，在OnlinePlayerGet中调用
+func recordOnlineActivity(roomID int, playTime map[string]int64) {
+	hour := currentHour()
+	for uid, seconds := range playTime {
+		if seconds == 0 {
+			continue
+		}
+		if err := DBHandler.playerActivityDao.RecordPlayTime(roomID, uid, hour, seconds); err != nil {
+			logger.Logger.Error("记录玩家活跃数据失败", "err", err)
+			return
+		}
+	}
+	if err := DBHandler.playerActivityDao.RecordRoomPeak(roomID, hour, len(playTime)); err != nil {
+		logger.Logger.Error("记录房间在线人数失败", "err", err)
+	}
+}
+
+// PlayerStatsGet 采样玩家存活天数和死亡次数，用于排行榜
+func PlayerStatsGet() {
+	roomsBasic, err := DBHandler.roomDao.GetRoomBasic()
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		return
+	}
+
+	hour := currentHour()
+	for _, rbs := range *roomsBasic {
+		if !rbs.Status {
+			continue
+		}
+		room, worlds, roomSetting, err := fetchGameInfo(rbs.RoomID)
+		if err != nil {
+			logger.Logger.Error("查询数据库失败", "err", err)
+			continue
+		}
+		game := dst.NewGameController(room, worlds, roomSetting, "zh")
+		for _, sample := range game.SamplePlayerStats() {
+			err = DBHandler.playerActivityDao.RecordStats(rbs.RoomID, sample.UID, hour, max(sample.Days, 0), sample.Deaths)
+			if err != nil {
+				logger.Logger.Error("记录玩家活跃数据失败", "err", err)
+			}
+		}
+	}
+}
+
+// GenerateActivityReport 生成指定时间范围的活跃报告，roomID为0时统计所有房间，同一周期的报告会被覆盖
+func GenerateActivityReport(roomID int, start, end int64, createdBy string) (*models.ActivityReport, error) {
+	activityDao := DBHandler.playerActivityDao
+	data := ActivityReportData{
+		RoomID:      roomID,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+
+	peak, err := activityDao.GetPeakPlayers(roomID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	data.PeakPlayers = peak.PeakPlayers
+	data.PeakAt = peak.Hour
+
+	data.Players, err = activityDao.GetActivitySummaries(roomID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	data.UniquePlayers = len(data.Players)
+	activeUIDs := make([]string, 0, len(data.Players))
+	for _, player := range data.Players {
+		data.TotalPlayTime += player.PlayTime
+		data.TotalDeaths += player.Deaths
+		activeUIDs = append(activeUIDs, player.UID)
+	}
+
+	data.NewPlayerUIDs, err = activityDao.GetNewPlayers(roomID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	data.NewPlayers = len(data.NewPlayerUIDs)
+
+	previousNew, err := activityDao.GetNewPlayers(roomID, start-(end-start), start)
+	if err != nil {
+		return nil, err
+	}
+	data.PreviousNewPlayers = len(previousNew)
+	for _, uid := range previousNew {
+		if slices.Contains(activeUIDs, uid) {
+			data.RetainedPlayers++
+		}
+	}
+	if data.PreviousNewPlayers != 0 {
+		data.Retention = float64(data.RetainedPlayers) / float64(data.PreviousNewPlayers)
+	}
+
+	hours, err := activityDao.GetHourlyPlayTime(roomID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, hour := range hours {
+		data.ActiveHours[time.UnixMilli(hour.Hour).Hour()] += hour.Seconds
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := activityDao.GetReportByPeriod(roomID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	report.RoomID = roomID
+	report.PeriodStart = start
+	report.PeriodEnd = end
+	report.Data = string(content)
+	report.CreatedBy = createdBy
+	report.CreatedAt = utils.GetTimestamp()
+	if err = activityDao.SaveReport(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// WeeklyActivityReport 每周一生成上一周每个房间和所有房间的活跃报告，并清理过期的活跃记录
+func WeeklyActivityReport() {
+	now := time.Now()
+	if now.Weekday() != time.Monday {
+		return
+	}
+
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := end.AddDate(0, 0, -7)
+
+	roomsBasic, err := DBHandler.roomDao.GetRoomBasic()
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		return
+	}
+
+	roomIDs := []int{0}
+	for _, rbs := range *roomsBasic {
+		roomIDs = append(roomIDs, rbs.RoomID)
+	}
+	for _, roomID := range roomIDs {
+		if _, err = GenerateActivityReport(roomID, start.UnixMilli(), end.UnixMilli(), "system"); err != nil {
+			logger.Logger.Error("生成活跃报告失败", "err", err, "room", roomID)
+		}
+	}
+	logger.Logger.Info("[定时任务]：活跃报告生成完成")
+
+	if err = DBHandler.playerActivityDao.DeleteActivityBefore(end.AddDate(0, 0, -activityRetentionDays).UnixMilli()); err != nil {
+		logger.Logger.Error("清理活跃记录失败", "err", err)
+	}
+}
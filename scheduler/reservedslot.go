@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"dst-management-platform-api/database/db"
+	"dst-management-platform-api/database/models"
+	"dst-management-platform-api/dst"
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+	"strings"
+)
+
+// updatePlayersJoinedAt 记录在线玩家的加入时间，返回本次新加入的玩家，players为GetOnlinePlayerList的结果
+// 房间第一次采样时无法判断加入顺序，所有玩家都不视为新加入
+func updatePlayersJoinedAt(roomID int, players []string) []string {
+	now := utils.GetTimestamp()
+
+	db.PlayersJoinedAtMutex.Lock()
+	defer db.PlayersJoinedAtMutex.Unlock()
+
+	lastJoinedAt, sampled := db.PlayersJoinedAt[roomID]
+	joinedAt := make(map[string]int64)
+	var newPlayers []string
+
+	for _, player := range players {
+		uid := strings.Split(player, "<-@dmp@->")[0]
+		if t, ok := lastJoinedAt[uid]; ok {
+			joinedAt[uid] = t
+			continue
+		}
+		joinedAt[uid] = now
+		if sampled {
+			newPlayers = append(newPlayers, uid)
+		}
+	}
+
+	// 离线玩家的记录不再保留
+	db.PlayersJoinedAt[roomID] = joinedAt
+
+	return newPlayers
+}
+
+// kickForVip 满员时每有一个VIP加入，踢出一个最近加入的非VIP玩家，管理员不会被踢出
+func kickForVip(room *models.Room, game *dst.Game, setting dst.ReservedSlotSetting, newPlayers []string, onlineCount int) {
+	if !setting.KickNonVip || onlineCount < room.MaxPlayer {
+		return
+	}
+
+	var vipJoined int
+	for _, uid := range newPlayers {
+		if utils.Contains(setting.VipList, uid) {
+			vipJoined++
+		}
+	}
+	if vipJoined == 0 {
+		return
+	}
+
+	adminlist := game.GetPlayerList("adminlist")
+
+	db.PlayersJoinedAtMutex.Lock()
+	defer db.PlayersJoinedAtMutex.Unlock()
+
+	joinedAt := db.PlayersJoinedAt[room.ID]
+	for i := 0; i < vipJoined; i++ {
+		var (
+			newest     string
+			newestTime int64
+		)
+		for uid, t := range joinedAt {
+			if utils.Contains(setting.VipList, uid) || utils.Contains(adminlist, uid) {
+				continue
+			}
+			if newest == "" || t > newestTime {
+				newest = uid
+				newestTime = t
+			}
+		}
+		if newest == "" {
+			return
+		}
+
+		if err := game.PlayerAction(dst.PlayerAction{Action: dst.PlayerActionKick, UID: newest}); err != nil {
+			logger.Logger.Warn("为VIP踢出玩家失败", "err", err, "uid", newest)
+			return
+		}
+		logger.Logger.Info("服务器满员，为VIP踢出最近加入的玩家", "room", room.ID, "uid", newest)
+		delete(joinedAt, newest)
+	}
+}
+
+// clearPlayersJoinedAt 关闭预留位后清除房间的玩家加入时间
+func clearPlayersJoinedAt(roomID int) {
+	db.PlayersJoinedAtMutex.Lock()
+	delete(db.PlayersJoinedAt, roomID)
+	db.PlayersJoinedAtMutex.Unlock()
+}
@@ -4,7 +4,6 @@ import (
 	"dst-management-platform-api/database/models"
 	"dst-management-platform-api/dst"
 	"dst-management-platform-api/logger"
-	"dst-management-platform-api/utils"
 	"fmt"
 	"time"
 )
@@ -18,9 +17,27 @@ func Backup(game *dst.Game) {
 	logger.Logger.Info("备份任务执行成功")
 }
 
-func BackupClean(roomID int, days int) {
-	backupPath := fmt.Sprintf("%s/backup/%d", utils.DmpFiles, roomID)
-	count, err := utils.RemoveFilesOlderThan(backupPath, days)
+func BackupClean(roomID int, days int, retentionSetting string) {
+	retention, err := dst.ParseBackupRetention(days, retentionSetting)
+	if err != nil {
+		logger.Logger.Error("解析备份保留策略失败", "err", err)
+		return
+	}
+
+	pinned, err := DBHandler.backupPinDao.GetPinnedFileNames(roomID)
+	if err != nil {
+		logger.Logger.Error("获取锁定备份失败", "err", err)
+		return
+	}
+
+	room, worlds, roomSetting, err := fetchGameInfo(roomID)
+	if err != nil {
+		logger.Logger.Error("获取房间信息失败", "err", err)
+		return
+	}
+	game := dst.NewGameController(room, worlds, roomSetting, "zh")
+
+	count, err := game.CleanBackups(retention, pinned)
 	if err != nil {
 		logger.Logger.Error("清理备份文件失败", "err", err)
 	}
@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"dst-management-platform-api/database/models"
+	"dst-management-platform-api/dst"
+	"dst-management-platform-api/logger"
+	"dst-management-platform-api/utils"
+)
+
+const (
+	// steamProfileRefreshInterval Steam资料的刷新间隔，单位毫秒
+	steamProfileRefreshInterval = 7 * 24 * 3600 * 1000
+	// steamProfileBatch 每次定时任务最多更新的玩家数量
+	steamProfileBatch = 200
+)
+
+// SteamProfileGet 从服务器日志中获取玩家的SteamID，并更新过期的Steam资料
+func SteamProfileGet(enable bool) {
+	if !enable {
+		return
+	}
+
+	ResolveSteamIDs()
+
+	players, err := DBHandler.playerDao.GetPlayersForSteamProfile(utils.GetTimestamp()-steamProfileRefreshInterval, steamProfileBatch)
+	if err != nil {
+		logger.Logger.Error("查询玩家目录失败", "err", err)
+		return
+	}
+	if err = EnrichSteamProfiles(players); err != nil {
+		logger.Logger.Warn("获取Steam玩家资料失败", "err", err)
+	}
+}
+
+// ResolveSteamIDs 读取所有激活房间的服务器日志，将SteamID记录到玩家目录
+func ResolveSteamIDs() {
+	roomsBasic, err := DBHandler.roomDao.GetRoomBasic()
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		return
+	}
+
+	for _, rbs := range *roomsBasic {
+		if !rbs.Status {
+			continue
+		}
+		ResolveSteamIDsForRoom(rbs.RoomID)
+	}
+}
+
+// ResolveSteamIDsForRoom 读取指定房间的服务器日志，将SteamID记录到玩家目录
+func ResolveSteamIDsForRoom(roomID int) {
+	room, worlds, roomSetting, err := fetchGameInfo(roomID)
+	if err != nil {
+		logger.Logger.Error("查询数据库失败", "err", err)
+		return
+	}
+	if room.ID == 0 {
+		return
+	}
+
+	game := dst.NewGameController(room, worlds, roomSetting, "zh")
+	for uid, steamID := range game.SteamIDsFromLog() {
+		if err = DBHandler.playerDao.UpdateSteamID(uid, steamID); err != nil {
+			logger.Logger.Error("更新玩家SteamID失败", "err", err, "uid", uid)
+		}
+	}
+}
+
+// EnrichSteamProfiles 获取玩家的Steam资料并保存，Steam没有返回资料的玩家只更新时间，避免反复查询
+func EnrichSteamProfiles(players *[]models.Player) error {
+	var steamIDs []string
+	for _, player := range *players {
+		if player.SteamID != "" {
+			steamIDs = append(steamIDs, player.SteamID)
+		}
+	}
+	if len(steamIDs) == 0 {
+		return nil
+	}
+
+	summaries, err := dst.SteamProfile.GetPlayerSummaries(utils.RemoveDuplicates(steamIDs))
+	if err != nil {
+		return err
+	}
+
+	now := utils.GetTimestamp()
+	for _, player := range *players {
+		if player.SteamID == "" {
+			continue
+		}
+		if summary, ok := summaries[player.SteamID]; ok {
+			player.SteamName = summary.PersonaName
+			player.SteamAvatar = summary.AvatarFull
+			player.SteamCreatedAt = summary.TimeCreated * 1000
+		}
+		player.SteamUpdatedAt = now
+		if err = DBHandler.playerDao.UpdateSteamProfile(&player); err != nil {
+			logger.Logger.Error("更新玩家Steam资料失败", "err", err, "uid", player.UID)
+		}
+	}
+
+	return nil
+}
@@ -42,20 +42,34 @@ const (
 )
 
 type Handler struct {
-	roomDao          *dao.RoomDAO
-	worldDao         *dao.WorldDAO
-	roomSettingDao   *dao.RoomSettingDAO
-	globalSettingDao *dao.GlobalSettingDAO
-	uidMapDao        *dao.UidMapDAO
+	roomDao             *dao.RoomDAO
+	worldDao            *dao.WorldDAO
+	roomSettingDao      *dao.RoomSettingDAO
+	globalSettingDao    *dao.GlobalSettingDAO
+	uidMapDao           *dao.UidMapDAO
+	backupPinDao        *dao.BackupPinDAO
+	modVersionDao       *dao.ModVersionDAO
+	modDownloadJobDao   *dao.ModDownloadJobDAO
+	playerDao           *dao.PlayerDAO
+	playerBanDao        *dao.PlayerBanDAO
+	globalPlayerListDao *dao.GlobalPlayerListDAO
+	playerActivityDao   *dao.PlayerActivityDAO
 }
 
-func newDBHandler(roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO, globalSettingDao *dao.GlobalSettingDAO, uidMapDao *dao.UidMapDAO) *Handler {
+func newDBHandler(roomDao *dao.RoomDAO, worldDao *dao.WorldDAO, roomSettingDao *dao.RoomSettingDAO, globalSettingDao *dao.GlobalSettingDAO, uidMapDao *dao.UidMapDAO, backupPinDao *dao.BackupPinDAO, modVersionDao *dao.ModVersionDAO, modDownloadJobDao *dao.ModDownloadJobDAO, playerDao *dao.PlayerDAO, playerBanDao *dao.PlayerBanDAO, globalPlayerListDao *dao.GlobalPlayerListDAO, playerActivityDao *dao.PlayerActivityDAO) *Handler {
 	return &Handler{
-		roomDao:          roomDao,
-		worldDao:         worldDao,
-		roomSettingDao:   roomSettingDao,
-		globalSettingDao: globalSettingDao,
-		uidMapDao:        uidMapDao,
+		roomDao:             roomDao,
+		worldDao:            worldDao,
+		roomSettingDao:      roomSettingDao,
+		globalSettingDao:    globalSettingDao,
+		uidMapDao:           uidMapDao,
+		backupPinDao:        backupPinDao,
+		modVersionDao:       modVersionDao,
+		modDownloadJobDao:   modDownloadJobDao,
+		playerDao:           playerDao,
+		playerBanDao:        playerBanDao,
+		globalPlayerListDao: globalPlayerListDao,
+		playerActivityDao:   playerActivityDao,
 	}
 }
 
@@ -5,10 +5,11 @@ import (
 )
 
 var (
-	bindPort    int
-	dbPath      string
-	logLevel    string
-	versionShow bool
+	bindPort       int
+	dbPath         string
+	logLevel       string
+	versionShow    bool
+	trustedProxies string
 )
 
 func bindFlags() {
@@ -16,5 +17,6 @@ func bindFlags() {
 	flag.StringVar(&dbPath, "dbpath", "./data", "数据库文件目录, 如: -dbpath ./data")
 	flag.StringVar(&logLevel, "level", "info", "日志等级, 如: -level debug")
 	flag.BoolVar(&versionShow, "v", false, "查看版本，如： -v")
+	flag.StringVar(&trustedProxies, "proxies", "", "信任的反向代理IP或网段，多个用逗号分隔，默认不信任X-Forwarded-For, 如: -proxies 127.0.0.1")
 	flag.Parse()
 }
@@ -18,6 +18,7 @@ import (
 	"dst-management-platform-api/utils"
 	"fmt"
 	"runtime"
+	"strings"
 
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
@@ -49,13 +50,32 @@ func Run() {
 	worldDao := dao.NewWorldDAO(db.DB)
 	globalSettingDao := dao.NewGlobalSettingDAO(db.DB)
 	uidMapDao := dao.NewUidMapDAO(db.DB)
+	backupPinDao := dao.NewBackupPinDAO(db.DB)
+	modVersionDao := dao.NewModVersionDAO(db.DB)
+	modDownloadJobDao := dao.NewModDownloadJobDAO(db.DB)
+	modProfileDao := dao.NewModProfileDAO(db.DB)
+	localModDao := dao.NewLocalModDAO(db.DB)
+	playerDao := dao.NewPlayerDAO(db.DB)
+	playerBanDao := dao.NewPlayerBanDAO(db.DB)
+	globalPlayerListDao := dao.NewGlobalPlayerListDAO(db.DB)
+	whitelistDao := dao.NewWhitelistDAO(db.DB)
+	playerActivityDao := dao.NewPlayerActivityDAO(db.DB)
 
 	// 开启定时任务
-	scheduler.Start(roomDao, worldDao, roomSettingDao, globalSettingDao, uidMapDao)
+	scheduler.Start(roomDao, worldDao, roomSettingDao, globalSettingDao, uidMapDao, backupPinDao, modVersionDao, modDownloadJobDao, playerDao, playerBanDao, globalPlayerListDao, playerActivityDao)
 
 	// 初始化及注册路由
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
+	// 只信任指定的反向代理，否则客户端可以伪造X-Forwarded-For绕过按IP的限流
+	var proxies []string
+	if trustedProxies != "" {
+		proxies = strings.Split(trustedProxies, ",")
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		logger.Logger.Error("设置信任的反向代理失败", "err", err)
+		return
+	}
 	r.Use(middleware.CacheControl())
 
 	// bug日志等级下，注册pprof路由
@@ -64,13 +84,13 @@ func Run() {
 	}
 
 	user.NewHandler(userDao).RegisterRoutes(r)
-	room.NewHandler(userDao, roomDao, worldDao, roomSettingDao, globalSettingDao, uidMapDao).RegisterRoutes(r)
-	mod.NewHandler(roomDao, worldDao, roomSettingDao).RegisterRoutes(r)
+	room.NewHandler(userDao, roomDao, worldDao, roomSettingDao, globalSettingDao, uidMapDao, backupPinDao).RegisterRoutes(r)
+	mod.NewHandler(userDao, roomDao, worldDao, roomSettingDao, modVersionDao, modDownloadJobDao, modProfileDao, localModDao).RegisterRoutes(r)
 	dashboard.NewHandler(userDao, roomDao, worldDao, roomSettingDao, globalSettingDao).RegisterRoutes(r)
-	platform.NewHandler(userDao, roomDao, worldDao, systemDao, globalSettingDao, uidMapDao, roomSettingDao).RegisterRoutes(r)
+	platform.NewHandler(userDao, roomDao, worldDao, systemDao, globalSettingDao, uidMapDao, roomSettingDao, playerDao, globalPlayerListDao).RegisterRoutes(r)
 	logs.NewHandler(userDao, roomDao, worldDao, roomSettingDao).RegisterRoutes(r)
-	tools.NewHandler(userDao, roomDao, worldDao, roomSettingDao).RegisterRoutes(r)
-	player.NewHandler(userDao, roomDao, worldDao, roomSettingDao, uidMapDao).RegisterRoutes(r)
+	tools.NewHandler(userDao, roomDao, worldDao, roomSettingDao, backupPinDao).RegisterRoutes(r)
+	player.NewHandler(userDao, roomDao, worldDao, roomSettingDao, globalSettingDao, uidMapDao, playerDao, playerBanDao, whitelistDao, playerActivityDao).RegisterRoutes(r)
 
 	r.Use(static.ServeEmbed("dist", embedFS.Dist))
 
@@ -22,6 +22,8 @@ const SteamApiModDetail = "http://api.steampowered.com/IPublishedFileService/Get
 
 const SteamApiModSearch = "http://api.steampowered.com/IPublishedFileService/QueryFiles/v1/"
 
+const SteamApiPlayerSummary = "http://api.steampowered.com/ISteamUser/GetPlayerSummaries/v2/"
+
 const ClusterPath = ".klei/DoNotStarveTogether"
 
 const DmpFiles = "dmp_files"
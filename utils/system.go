@@ -4,6 +4,7 @@ import (
 	"archive/zip"
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,6 +13,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/cpu"
@@ -241,6 +243,37 @@ func BashCMD(cmd string) error {
 	return nil
 }
 
+// BashCMDStream 执行Linux Bash 命令，逐行回调标准输出，ctx取消时结束整个进程组
+func BashCMDStream(ctx context.Context, cmd string, onLine func(line string)) error {
+	cmdExec := exec.CommandContext(ctx, "/bin/bash", "-c", cmd)
+	cmdExec.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmdExec.Cancel = func() error {
+		return syscall.Kill(-cmdExec.Process.Pid, syscall.SIGKILL)
+	}
+
+	stdout, err := cmdExec.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmdExec.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if onLine != nil {
+			onLine(scanner.Text())
+		}
+	}
+
+	err = cmdExec.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
 // BashCMDOutput 执行Linux Bash 命令，并返回结果
 func BashCMDOutput(cmd string) (string, string, error) {
 	// 定义要执行的命令和参数
@@ -547,6 +580,12 @@ func ZipFiles(files []string, target string) error {
 
 // Unzip 解压ZIP文件
 func Unzip(zipFile, dest string) error {
+	return UnzipWithLimit(zipFile, dest, 0)
+}
+
+// UnzipWithLimit 解压ZIP文件，解压后的总大小超过maxSize字节时返回错误，maxSize为0时不限制
+// ZIP中记录的大小可以伪造，所以按实际写入的字节数计算
+func UnzipWithLimit(zipFile, dest string, maxSize int64) error {
 	// 打开ZIP文件
 	reader, err := zip.OpenReader(zipFile)
 	if err != nil {
@@ -559,26 +598,13 @@ func Unzip(zipFile, dest string) error {
 		return fmt.Errorf("创建目标目录失败: %v", err)
 	}
 
+	remaining := maxSize
 	// 遍历ZIP文件中的每个条目
 	for _, file := range reader.File {
-		// 关键修复：将Windows风格的反斜杠路径转换为当前系统的路径分隔符
-		name := file.Name
-
-		// 替换所有反斜杠为正斜杠
-		name = strings.ReplaceAll(name, "\\", "/")
-
-		// 清理路径
-		name = filepath.Clean(name)
-
 		// 构建完整路径
-		filePath := filepath.Join(dest, name)
-
-		// 安全检查：防止路径遍历攻击
-		cleanDest := filepath.Clean(dest)
-		cleanFilePath := filepath.Clean(filePath)
-		if !strings.HasPrefix(cleanFilePath, cleanDest+string(os.PathSeparator)) &&
-			cleanFilePath != cleanDest {
-			return fmt.Errorf("无效的文件路径: %s", filePath)
+		filePath, err := zipEntryPath(dest, zipEntryName(file.Name))
+		if err != nil {
+			return err
 		}
 
 		// 检查是否是目录
@@ -592,38 +618,187 @@ func Unzip(zipFile, dest string) error {
 			continue
 		}
 
-		// 确保文件的父目录存在
-		parentDir := filepath.Dir(filePath)
-		if err := os.MkdirAll(parentDir, 0755); err != nil {
-			return fmt.Errorf("创建父目录失败: %v", err)
+		if maxSize <= 0 {
+			err = unzipEntry(file, filePath)
+			if err != nil {
+				return err
+			}
+			continue
 		}
 
-		// 创建目标文件
-		outFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
+		written, err := unzipEntryLimit(file, filePath, remaining)
 		if err != nil {
-			return fmt.Errorf("创建文件失败: %v", err)
+			return err
 		}
+		remaining -= written
+	}
 
-		// 打开ZIP中的文件
-		rc, err := file.Open()
-		if err != nil {
-			outFile.Close()
-			return fmt.Errorf("打开ZIP内文件失败: %v", err)
-		}
+	return nil
+}
+
+// zipEntryName 规范ZIP条目名称
+func zipEntryName(name string) string {
+	// 关键修复：将Windows风格的反斜杠路径转换为当前系统的路径分隔符
+	// 替换所有反斜杠为正斜杠
+	name = strings.ReplaceAll(name, "\\", "/")
 
-		// 复制文件内容
-		_, err = io.Copy(outFile, rc)
+	// 清理路径
+	return filepath.Clean(name)
+}
+
+// zipEntryPath 构建ZIP条目的解压路径
+func zipEntryPath(dest, name string) (string, error) {
+	filePath := filepath.Join(dest, name)
+
+	// 安全检查：防止路径遍历攻击
+	cleanDest := filepath.Clean(dest)
+	cleanFilePath := filepath.Clean(filePath)
+	if !strings.HasPrefix(cleanFilePath, cleanDest+string(os.PathSeparator)) &&
+		cleanFilePath != cleanDest {
+		return "", fmt.Errorf("无效的文件路径: %s", filePath)
+	}
 
-		// 关闭文件句柄
+	return cleanFilePath, nil
+}
+
+// unzipEntry 解压单个ZIP条目到指定文件
+func unzipEntry(file *zip.File, filePath string) error {
+	_, err := unzipEntryLimit(file, filePath, -1)
+	return err
+}
+
+// unzipEntryLimit 解压单个ZIP条目，写入超过limit字节时返回错误，limit小于0时不限制，返回写入的字节数
+func unzipEntryLimit(file *zip.File, filePath string, limit int64) (int64, error) {
+	// 确保文件的父目录存在
+	parentDir := filepath.Dir(filePath)
+	if err := os.MkdirAll(parentDir, 0755); err != nil {
+		return 0, fmt.Errorf("创建父目录失败: %v", err)
+	}
+
+	// 创建目标文件
+	outFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
+	if err != nil {
+		return 0, fmt.Errorf("创建文件失败: %v", err)
+	}
+
+	// 打开ZIP中的文件
+	rc, err := file.Open()
+	if err != nil {
 		outFile.Close()
-		rc.Close()
+		return 0, fmt.Errorf("打开ZIP内文件失败: %v", err)
+	}
 
-		if err != nil {
-			return fmt.Errorf("写入文件失败: %v", err)
+	// 复制文件内容，限制大小时多读一个字节用于判断是否超出
+	var reader io.Reader = rc
+	if limit >= 0 {
+		reader = io.LimitReader(rc, limit+1)
+	}
+	written, err := io.Copy(outFile, reader)
+
+	// 关闭文件句柄
+	outFile.Close()
+	rc.Close()
+
+	if err != nil {
+		return written, fmt.Errorf("写入文件失败: %v", err)
+	}
+	if limit >= 0 && written > limit {
+		return written, fmt.Errorf("解压后的文件超出大小限制")
+	}
+
+	return written, nil
+}
+
+type ZipEntry struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	IsDir   bool      `json:"isDir"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// ListZip 列出ZIP文件中的所有条目
+func ListZip(zipFile string) ([]ZipEntry, error) {
+	reader, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return []ZipEntry{}, fmt.Errorf("打开ZIP文件失败: %v", err)
+	}
+	defer reader.Close()
+
+	var entries []ZipEntry
+	for _, file := range reader.File {
+		name := zipEntryName(file.Name)
+		// 跳过不安全的条目
+		if !filepath.IsLocal(name) {
+			continue
 		}
+		entries = append(entries, ZipEntry{
+			Name:    filepath.ToSlash(name),
+			Size:    int64(file.UncompressedSize64),
+			IsDir:   file.FileInfo().IsDir() || strings.HasSuffix(file.Name, "/"),
+			ModTime: file.Modified,
+		})
 	}
 
-	return nil
+	return entries, nil
+}
+
+// findZipEntry 根据规范后的名称查找ZIP条目
+func findZipEntry(reader *zip.ReadCloser, name string) (*zip.File, error) {
+	target := zipEntryName(name)
+	for _, file := range reader.File {
+		if zipEntryName(file.Name) == target && !file.FileInfo().IsDir() {
+			return file, nil
+		}
+	}
+
+	return nil, fmt.Errorf("ZIP中未找到文件: %s", name)
+}
+
+// ReadZipFile 读取ZIP中的单个文件，maxSize为允许读取的最大字节数
+func ReadZipFile(zipFile, name string, maxSize int64) ([]byte, error) {
+	reader, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return nil, fmt.Errorf("打开ZIP文件失败: %v", err)
+	}
+	defer reader.Close()
+
+	file, err := findZipEntry(reader, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(file.UncompressedSize64) > maxSize {
+		return nil, fmt.Errorf("文件过大: %s", name)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("打开ZIP内文件失败: %v", err)
+	}
+	defer rc.Close()
+
+	return io.ReadAll(io.LimitReader(rc, maxSize))
+}
+
+// UnzipFile 解压ZIP中的单个文件到dest目录下的target相对路径
+func UnzipFile(zipFile, name, dest, target string) error {
+	reader, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return fmt.Errorf("打开ZIP文件失败: %v", err)
+	}
+	defer reader.Close()
+
+	file, err := findZipEntry(reader, name)
+	if err != nil {
+		return err
+	}
+
+	filePath, err := zipEntryPath(dest, zipEntryName(target))
+	if err != nil {
+		return err
+	}
+
+	return unzipEntry(file, filePath)
 }
 
 // CpuUsage 获取cpu使用率